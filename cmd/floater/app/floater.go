@@ -2,13 +2,26 @@ package app
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
+	"strconv"
 	"time"
 
-	"github.com/kosmos.io/linkctl/cmd/floater/app/options"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/kosmos.io/linkctl/cmd/floater/app/options"
+	"github.com/kosmos.io/linkctl/pkg/mesh"
+	"github.com/kosmos.io/linkctl/pkg/probe"
+	"github.com/kosmos.io/linkctl/pkg/utils"
 )
 
 func NewFloaterCommand(ctx context.Context) *cobra.Command {
@@ -32,11 +45,12 @@ func NewFloaterCommand(ctx context.Context) *cobra.Command {
 			return nil
 		},
 	}
+	cmd.AddCommand(NewProbeCommand())
 
 	return cmd
 }
 
-func Run(_ context.Context, _ *options.Options) error {
+func Run(ctx context.Context, _ *options.Options) error {
 
 	port := os.Getenv("PORT")
 	if len(port) == 0 {
@@ -50,6 +64,35 @@ func Run(_ context.Context, _ *options.Options) error {
 		}
 	})
 
+	if enableProbeAPI, _ := strconv.ParseBool(os.Getenv(probe.EnvEnable)); enableProbeAPI {
+		http.HandleFunc("/"+probe.Path, serveProbe(port))
+	}
+
+	go func() {
+		if err := serveUDPEcho(port); err != nil {
+			fmt.Print(fmt.Errorf("launch udp echo server error: %s", err))
+			panic(err)
+		}
+	}()
+
+	var metrics *mesh.Metrics
+	if enableAnalysis, _ := strconv.ParseBool(os.Getenv(mesh.EnvEnableAnalysis)); enableAnalysis {
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+		metrics = mesh.NewMetrics(registry)
+		http.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	}
+
+	if name := os.Getenv(mesh.EnvConfigMap); len(name) > 0 {
+		agent, err := newMeshAgent(name)
+		if err != nil {
+			fmt.Print(fmt.Errorf("launch mesh agent error: %s", err))
+			return err
+		}
+		agent.Metrics = metrics
+		go agent.Run(ctx)
+	}
+
 	server := &http.Server{
 		Addr:              fmt.Sprintf(":%s", port),
 		ReadHeaderTimeout: 3 * time.Second,
@@ -63,3 +106,73 @@ func Run(_ context.Context, _ *options.Options) error {
 
 	return nil
 }
+
+// newMeshAgent builds the agent probing the peers listed in the ConfigMap name, it talks to the API
+// server with the service account of the floater.
+func newMeshAgent(name string) (*mesh.Agent, error) {
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, err
+	}
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	agent := &mesh.Agent{
+		Client:    client,
+		Namespace: os.Getenv(mesh.EnvNamespace),
+		Name:      name,
+		NodeName:  os.Getenv(utils.EnvNodeName),
+	}
+	if len(agent.Namespace) == 0 || len(agent.NodeName) == 0 {
+		return nil, fmt.Errorf("%s and %s must be set in mesh mode", mesh.EnvNamespace, utils.EnvNodeName)
+	}
+	return agent, nil
+}
+
+// serveUDPEcho sends every datagram back to its sender, it is used to probe the udp path between floaters.
+func serveUDPEcho(port string) error {
+	conn, err := net.ListenPacket("udp", fmt.Sprintf(":%s", port))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return err
+			}
+			// back off so that a persistent error does not spin
+			fmt.Println(fmt.Errorf("udp read error: %s", err))
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+		if _, err = conn.WriteTo(buf[:n], addr); err != nil {
+			fmt.Println(fmt.Errorf("udp write error: %s", err))
+		}
+	}
+}
+
+// serveProbe runs the probe described by the query natively and responds with its result as json, the
+// probes reach the floater port only.
+func serveProbe(port string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := probe.ParseRequest(r.URL.Query())
+		if err == nil {
+			err = req.Authorize(port)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err = json.NewEncoder(w).Encode(probe.Run(req)); err != nil {
+			fmt.Print(fmt.Errorf("response writer error: %s", err))
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kosmos.io/linkctl/pkg/probe"
+)
+
+// NewProbeCommand runs one probe natively and prints its result as json, linkctl execs it for the probes the
+// shell tools of the image can not run, like the path MTU discovery.
+func NewProbeCommand() *cobra.Command {
+	req := &probe.Request{
+		Timeout:  3,
+		Count:    1,
+		Interval: 1,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "probe",
+		Short: "Run one probe and print its result as json",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(req.Protocol) == 0 || len(req.TargetIP) == 0 {
+				return fmt.Errorf("--protocol and --target must be set")
+			}
+			return json.NewEncoder(os.Stdout).Encode(probe.Run(req))
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&req.Protocol, "protocol", "", "Protocol of the probe.")
+	flags.StringVar(&req.TargetIP, "target", "", "IP address to probe.")
+	flags.StringVar(&req.Port, "port", "", "Port to probe.")
+	flags.IntVar(&req.Timeout, "timeout", req.Timeout, "Timeout of the whole probe in seconds.")
+
+	return cmd
+}
@@ -0,0 +1,128 @@
+package floater
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+const (
+	// DirectionForward the source cluster probes the destination cluster
+	DirectionForward = "forward"
+	// DirectionReverse the destination cluster probes the source cluster
+	DirectionReverse = "reverse"
+)
+
+// reverse returns the mode of the return path, the networks of the source and the destination swap.
+func (m CheckMode) reverse() CheckMode {
+	switch m {
+	case ModePodNode:
+		return ModeNodePod
+	case ModeNodePod:
+		return ModePodNode
+	default:
+		return m
+	}
+}
+
+// reverse returns the options probing from the destination floaters toward the source floaters, the
+// source IPs are mapped with the globalCIDRsMap of the source cluster.
+func (o *CommandCheckOptions) reverse() *CommandCheckOptions {
+	reverse := *o
+	reverse.SrcFloater, reverse.DstFloater = o.DstFloater, o.SrcFloater
+	return &reverse
+}
+
+// AsymmetricPath is a pair of nodes whose checks fail in one direction only, which points at an asymmetric
+// NAT or at a missing return route rather than at the nodes.
+type AsymmetricPath struct {
+	Mode        string `json:"mode,omitempty"`
+	SrcNodeName string `json:"srcNodeName"`
+	DstNodeName string `json:"dstNodeName"`
+	Forward     string `json:"forward"`
+	Reverse     string `json:"reverse"`
+
+	forward *matrixCell
+	reverse *matrixCell
+}
+
+type pathKey struct {
+	Mode        string
+	SrcNodeName string
+	DstNodeName string
+}
+
+// FindAsymmetricPaths pairs every forward check with the reverse check of the same nodes, the reverse
+// checks are keyed by the forward mode and nodes.
+func FindAsymmetricPaths(resultData []*PrintCheckData) []AsymmetricPath {
+	noWarning := func(*PrintCheckData) string { return "" }
+	forward := map[pathKey]*matrixCell{}
+	reverse := map[pathKey]*matrixCell{}
+	for _, r := range resultData {
+		var key pathKey
+		var cells map[pathKey]*matrixCell
+		switch r.Direction {
+		case DirectionForward:
+			key = pathKey{r.Mode, r.SrcNodeName, r.DstNodeName}
+			cells = forward
+		case DirectionReverse:
+			key = pathKey{string(CheckMode(r.Mode).reverse()), r.DstNodeName, r.SrcNodeName}
+			cells = reverse
+		default:
+			continue
+		}
+		cell, ok := cells[key]
+		if !ok {
+			cell = &matrixCell{}
+			cells[key] = cell
+		}
+		cell.add(r, noWarning)
+	}
+
+	var paths []AsymmetricPath
+	for key, f := range forward {
+		r, ok := reverse[key]
+		if !ok || (f.Failed > 0) == (r.Failed > 0) {
+			continue
+		}
+		paths = append(paths, AsymmetricPath{
+			Mode:        key.Mode,
+			SrcNodeName: key.SrcNodeName,
+			DstNodeName: key.DstNodeName,
+			Forward:     f.String(),
+			Reverse:     r.String(),
+			forward:     f,
+			reverse:     r,
+		})
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		if paths[i].Mode != paths[j].Mode {
+			return paths[i].Mode < paths[j].Mode
+		}
+		if paths[i].SrcNodeName != paths[j].SrcNodeName {
+			return paths[i].SrcNodeName < paths[j].SrcNodeName
+		}
+		return paths[i].DstNodeName < paths[j].DstNodeName
+	})
+	return paths
+}
+
+func printAsymmetricPaths(paths []AsymmetricPath) {
+	if len(paths) == 0 {
+		return
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"MODE", "SRC_NODE_NAME", "DST_NODE_NAME", "SRC->DST", "DST->SRC"})
+	for _, p := range paths {
+		table.Rich([]string{p.Mode, p.SrcNodeName, p.DstNodeName, p.Forward, p.Reverse}, []tablewriter.Colors{
+			{}, {}, {},
+			{tablewriter.Bold, p.forward.Color()},
+			{tablewriter.Bold, p.reverse.Color()},
+		})
+	}
+	fmt.Println("")
+	fmt.Printf("ASYMMETRIC PATHS: %d\n", len(paths))
+	table.Render()
+}
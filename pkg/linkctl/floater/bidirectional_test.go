@@ -0,0 +1,52 @@
+package floater
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kosmos.io/linkctl/pkg/linkctl/floater/command"
+)
+
+func TestFindAsymmetricPaths(t *testing.T) {
+	probe := func(direction string, mode CheckMode, src, dst string, status int) *PrintCheckData {
+		return &PrintCheckData{
+			Result:      command.Result{Status: status},
+			SrcNodeName: src,
+			DstNodeName: dst,
+			Mode:        string(mode),
+			Direction:   direction,
+		}
+	}
+	ok, fail := command.CommandSuccessed, command.CommandFailed
+
+	resultData := []*PrintCheckData{
+		// node-1 and node-2 reach each other
+		probe(DirectionForward, ModePodPod, "node-1", "node-2", ok),
+		probe(DirectionReverse, ModePodPod, "node-2", "node-1", ok),
+		// the return route to node-3 is missing
+		probe(DirectionForward, ModePodPod, "node-1", "node-3", ok),
+		probe(DirectionReverse, ModePodPod, "node-3", "node-1", fail),
+		// the reverse of pod-node is node-pod, one of the two node IPs fails forward
+		probe(DirectionForward, ModePodNode, "node-1", "node-2", ok),
+		probe(DirectionForward, ModePodNode, "node-1", "node-2", fail),
+		probe(DirectionReverse, ModeNodePod, "node-2", "node-1", ok),
+		// failing both ways is not asymmetric
+		probe(DirectionForward, ModePodPod, "node-1", "node-4", fail),
+		probe(DirectionReverse, ModePodPod, "node-4", "node-1", fail),
+		// without a reverse check there is nothing to compare
+		probe(DirectionForward, ModePodPod, "node-1", "node-5", fail),
+		probe("", ModePodPod, "node-5", "node-1", ok),
+	}
+
+	var got [][]string
+	for _, p := range FindAsymmetricPaths(resultData) {
+		got = append(got, []string{p.Mode, p.SrcNodeName, p.DstNodeName, p.Forward, p.Reverse})
+	}
+	want := [][]string{
+		{"pod-node", "node-1", "node-2", "1/2", "OK"},
+		{"pod-pod", "node-1", "node-3", "OK", "FAIL"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindAsymmetricPaths() = %q, want %q", got, want)
+	}
+}
@@ -2,12 +2,15 @@ package floater
 
 import (
 	"fmt"
-	"os"
-	"strconv"
+	"net"
 	"sync"
 
-	"github.com/olekukonko/tablewriter"
+	progressbar "github.com/schollz/progressbar/v3"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/klog/v2"
 	ctlutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/util/i18n"
@@ -29,36 +32,187 @@ var checkExample = templates.Examples(i18n.T(`
         
         # Check cluster network connectivity, if you need to specify a special image repository, e.g: 
         linkctl check -r ghcr.io/kosmos-io
+
+        # Check across clusters that use global CIDRs, the globalCIDRsMap is read from the Kosmos cluster object, e.g:
+        linkctl check --src-kubeconfig ~/kubeconfig/src-kubeconfig --dst-kubeconfig ~/kubeconfig/dst-kubeconfig --dst-cluster-name cluster-b
+
+        # Check a large cluster and print the results as a source node x destination node grid, e.g:
+        linkctl check --src-kubeconfig ~/kubeconfig/src-kubeconfig --view matrix
+
+        # Measure latency, jitter and packet loss with 10 pings per target and report paths slower than 5ms, e.g:
+        linkctl check --src-kubeconfig ~/kubeconfig/src-kubeconfig --count 10 --interval 0.2 --cmd-timeout 5 --latency-threshold 5 -o wide
+
+        # Discover the path MTU between every pair of floaters, e.g:
+        linkctl check --src-kubeconfig ~/kubeconfig/src-kubeconfig --protocol mtu --cmd-timeout 15
+
+        # Let the floaters run the probes natively instead of opening an exec session per probe, e.g:
+        linkctl check --src-kubeconfig ~/kubeconfig/src-kubeconfig --probe-mode api
+
+        # Probe all targets of a source floater concurrently in a single exec session, e.g:
+        linkctl check --src-kubeconfig ~/kubeconfig/src-kubeconfig --batch
+
+        # Also check that the floaters reach each other through a Service exported and imported with Kosmos MCS, e.g:
+        linkctl check --src-kubeconfig ~/kubeconfig/src-kubeconfig --dst-kubeconfig ~/kubeconfig/dst-kubeconfig --services
+
+        # Check DNS resolution through kube-dns and the Kosmos CoreDNS, e.g:
+        linkctl check dns --src-kubeconfig ~/kubeconfig/src-kubeconfig
+
+        # Also probe from the destination floaters toward the source cluster and report the asymmetric paths, e.g:
+        linkctl check --src-kubeconfig ~/kubeconfig/src-kubeconfig --dst-kubeconfig ~/kubeconfig/dst-kubeconfig --bidirectional --src-cluster-name cluster-a --dst-cluster-name cluster-b
+
+        # Check every ordered pair of a list of clusters and print a cluster x cluster summary, e.g:
+        linkctl check --kubeconfig-list $HOME/kubeconfig/a,$HOME/kubeconfig/b,$HOME/kubeconfig/c
+
+        # Drill down into the nodes of every pair of the clusters of some contexts, e.g:
+        linkctl check --contexts ctx-a,ctx-b,ctx-c --view matrix
+
+        # Check the whole Kosmos fleet, the clusters are read from the cluster objects of the control cluster, e.g:
+        linkctl check --src-kubeconfig ~/kubeconfig/control-kubeconfig --all-clusters
+
+        # Check pod to pod, node to node, pod to node and node to pod connectivity in one run, e.g:
+        linkctl check --src-kubeconfig ~/kubeconfig/src-kubeconfig --mode all
+
+        # Check TCP connectivity to the floater port instead of ICMP, e.g:
+        linkctl check --src-kubeconfig ~/kubeconfig/src-kubeconfig --protocol tcp
 `))
 
 var (
 	once sync.Once
 )
 
+// CheckMode is the network of the source and of the destination floaters of the checked pairs.
+type CheckMode string
+
+const (
+	ModePodPod   CheckMode = "pod-pod"
+	ModeNodeNode CheckMode = "node-node"
+	ModePodNode  CheckMode = "pod-node"
+	ModeNodePod  CheckMode = "node-pod"
+	ModeAll      CheckMode = "all"
+)
+
+var SupportedModes = []CheckMode{ModePodPod, ModeNodeNode, ModePodNode, ModeNodePod, ModeAll}
+
+func IsSupportedMode(mode string) bool {
+	for _, m := range SupportedModes {
+		if string(m) == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// hostNetworks tells whether the source and the destination floaters of the mode run in the host network.
+func (m CheckMode) hostNetworks() (bool, bool) {
+	switch m {
+	case ModeNodeNode:
+		return true, true
+	case ModePodNode:
+		return false, true
+	case ModeNodePod:
+		return true, false
+	default:
+		return false, false
+	}
+}
+
 type CommandCheckOptions struct {
 	Namespace          string `json:"namespace,omitempty"`
 	ImageRepository    string `json:"imageRepository,omitempty"`
 	DstImageRepository string `json:"dstImageRepository,omitempty"`
-	Version            string `json:"version,omitempty"`
+	// Version is the floater image of this linkctl, it is not saved so that resume never deploys an older image
+	// without the probes the run relies on
+	Version string `json:"-"`
 
-	Protocol    string `json:"protocol,omitempty"`
+	// Protocol is saved as probeProtocol, the protocol of older config files was not used by the check which
+	// always pinged
+	Protocol    string `json:"probeProtocol,omitempty"`
 	PodWaitTime int    `json:"podWaitTime,omitempty"`
 	Port        string `json:"port,omitempty"`
 	HostNetwork bool   `json:"hostNetwork,omitempty"`
+	Mode        string `json:"mode,omitempty"`
+
+	EnableAnalysis bool `json:"enableAnalysis,omitempty"`
+
+	// Namespaced only uses objects of an existing namespace, for operators without cluster-wide permissions
+	Namespaced bool `json:"namespaced,omitempty"`
+
+	NodeSelector  map[string]string `json:"nodeSelector,omitempty"`
+	Nodes         []string          `json:"nodes,omitempty"`
+	ExcludeNodes  []string          `json:"excludeNodes,omitempty"`
+	Sample        int               `json:"sample,omitempty"`
+	SamplePerZone int               `json:"samplePerZone,omitempty"`
 
 	SrcKubeConfig string `json:"srcKubeConfig,omitempty"`
 	DstKubeConfig string `json:"dstKubeConfig,omitempty"`
+	SrcContext    string `json:"srcContext,omitempty"`
+	DstContext    string `json:"dstContext,omitempty"`
+
+	KubeConfigList []string `json:"kubeConfigList,omitempty"`
+	Contexts       []string `json:"contexts,omitempty"`
+	AllClusters    bool     `json:"allClusters,omitempty"`
+
+	DstClusterName string            `json:"dstClusterName,omitempty"`
+	CIDRsMap       map[string]string `json:"cidrsMap,omitempty"`
+
+	Bidirectional  bool              `json:"bidirectional,omitempty"`
+	SrcClusterName string            `json:"srcClusterName,omitempty"`
+	SrcCIDRsMap    map[string]string `json:"srcCIDRsMap,omitempty"`
 
 	MaxNum int `json:"maxNum,omitempty"`
 
 	AutoClean bool `json:"autoClean,omitempty"`
 
-	CmdTimeout int `json:"cmdTimeout,omitempty"`
+	CmdTimeout int    `json:"cmdTimeout,omitempty"`
+	ProbeMode  string `json:"probeMode,omitempty"`
+	Batch      bool   `json:"batch,omitempty"`
+
+	Services      bool   `json:"services,omitempty"`
+	ClusterDomain string `json:"clusterDomain,omitempty"`
+
+	Count      int     `json:"count,omitempty"`
+	Interval   float64 `json:"interval,omitempty"`
+	PacketSize int     `json:"packetSize,omitempty"`
+
+	LatencyThreshold float64 `json:"latencyThreshold,omitempty"`
+	LossThreshold    float64 `json:"lossThreshold,omitempty"`
+
+	FailThreshold float64 `json:"failThreshold,omitempty"`
+
+	// RunID scopes the names of the floater objects, it is kept in config.json for resume and clean
+	RunID string `json:"runId,omitempty"`
+	// Owner started the run of RunID, the next check of the same operator continues the run
+	Owner string `json:"owner,omitempty"`
+	// newRun is set when the run ID was generated by this command
+	newRun bool
+	// reuseRun continues the run of config.json whoever started it, check only continues the runs of its operator
+	reuseRun bool
+
+	Output string `json:"-"`
+	View   string `json:"-"`
+
+	// ConfigFlags are the standard kubeconfig flags of linkctl
+	ConfigFlags *genericclioptions.ConfigFlags `json:"-"`
+	// flags of the command, the ones set explicitly take precedence over config.json
+	flags *pflag.FlagSet
 
 	SrcFloater *Floater `json:"-"`
 	DstFloater *Floater `json:"-"`
 
+	Members []*MemberCluster `json:"-"`
+
 	ResumeRecord []*PrintCheckData `json:"-"`
+	// scope labels the results of runTargets
+	scope checkScope
+}
+
+// checkScope is the mode, direction and clusters of a set of results, resume only re-runs the records of the
+// scope being checked.
+type checkScope struct {
+	Mode       string
+	Direction  string
+	SrcCluster string
+	DstCluster string
 }
 
 type PrintCheckData struct {
@@ -66,11 +220,20 @@ type PrintCheckData struct {
 	SrcNodeName string `json:"srcNodeName"`
 	DstNodeName string `json:"dstNodeName"`
 	TargetIP    string `json:"targetIP"`
+	SrcCluster  string `json:"srcCluster,omitempty"`
+	DstCluster  string `json:"dstCluster,omitempty"`
+	Mode        string `json:"mode,omitempty"`
+	Direction   string `json:"direction,omitempty"`
+}
+
+func (r *PrintCheckData) scope() checkScope {
+	return checkScope{Mode: r.Mode, Direction: r.Direction, SrcCluster: r.SrcCluster, DstCluster: r.DstCluster}
 }
 
-func NewOptions() (*cobra.Command, *CommandCheckOptions) {
+func NewOptions(configFlags *genericclioptions.ConfigFlags) (*cobra.Command, *CommandCheckOptions) {
 	o := &CommandCheckOptions{
-		Version: version.GetReleaseVersion().PatchRelease(),
+		Version:     version.GetReleaseVersion().PatchRelease(),
+		ConfigFlags: configFlags,
 	}
 	cmd := &cobra.Command{
 		Use:                   "check",
@@ -96,88 +259,412 @@ func NewOptions() (*cobra.Command, *CommandCheckOptions) {
 	}
 
 	flags := cmd.Flags()
+	o.flags = flags
 	flags.StringVarP(&o.Namespace, "namespace", "n", utils.DefaultNamespace, "Kosmos namespace.")
 	flags.StringVarP(&o.ImageRepository, "image-repository", "r", utils.DefaultImageRepository, "Image repository.")
 	flags.StringVarP(&o.DstImageRepository, "dst-image-repository", "", "", "Destination cluster image repository.")
-	flags.StringVar(&o.SrcKubeConfig, "src-kubeconfig", "", "Absolute path to the source cluster kubeconfig file.")
+	flags.StringVar(&o.SrcKubeConfig, "src-kubeconfig", "", "Absolute path to the source cluster kubeconfig file, defaults to --kubeconfig, KUBECONFIG or ~/.kube/config.")
 	flags.StringVar(&o.DstKubeConfig, "dst-kubeconfig", "", "Absolute path to the destination cluster kubeconfig file.")
+	flags.StringVar(&o.SrcContext, "src-context", "", "Context of the source cluster, defaults to --context or the current context.")
+	flags.StringVar(&o.DstContext, "dst-context", "", "Context of the destination cluster, it is read from dst-kubeconfig or from the kubeconfig of the source cluster.")
+	flags.StringSliceVar(&o.KubeConfigList, "kubeconfig-list", nil, "Kubeconfig files of the clusters whose every ordered pair is checked, instead of src-kubeconfig and dst-kubeconfig, a leading ~ of every file is expanded.")
+	flags.StringSliceVar(&o.Contexts, "contexts", nil, "Contexts of the default kubeconfig of the clusters whose every ordered pair is checked.")
+	flags.BoolVar(&o.AllClusters, "all-clusters", false, "Check every ordered pair of the clusters of the Kosmos cluster objects in the control cluster of src-kubeconfig.")
+	flags.StringVar(&o.DstClusterName, "dst-cluster-name", "", "Name of the Kosmos cluster object of the destination cluster, its globalCIDRsMap is used to map the target IPs.")
+	flags.StringToStringVar(&o.CIDRsMap, "cidrs-map", nil, "Global CIDRs map of the destination cluster, e.g. 10.222.0.0/16=210.222.0.0/16, overrides the one read from the cluster object.")
+	flags.BoolVar(&o.Bidirectional, "bidirectional", false, "Also probe from the destination floaters toward the source cluster and report the paths failing in one direction only.")
+	flags.StringVar(&o.SrcClusterName, "src-cluster-name", "", "Name of the Kosmos cluster object of the source cluster, its globalCIDRsMap is used to map the target IPs of --bidirectional.")
+	flags.StringToStringVar(&o.SrcCIDRsMap, "src-cidrs-map", nil, "Global CIDRs map of the source cluster used by --bidirectional, overrides the one read from the cluster object.")
 	flags.BoolVar(&o.HostNetwork, "host-network", false, "Configure HostNetwork.")
+	flags.StringVar(&o.Mode, "mode", "", "Pairs to check, one of pod-pod, node-node, pod-node, node-pod or all, defaults to node-node with --host-network and to pod-pod otherwise.")
 	flags.StringVar(&o.Port, "port", "8889", "Port used by floater.")
+	flags.BoolVar(&o.Namespaced, "namespaced", false, "Only use objects of the existing namespace of --namespace, the nodes are identified by the floater pods instead of the Node objects.")
+	flags.StringToStringVar(&o.NodeSelector, "node-selector", nil, "Labels of the nodes the floaters run on, e.g. node-role.kubernetes.io/worker=.")
+	flags.StringSliceVar(&o.Nodes, "nodes", nil, "Names of the nodes the floaters run on, defaults to every node.")
+	flags.StringSliceVar(&o.ExcludeNodes, "exclude-nodes", nil, "Names of the nodes kept free of floaters.")
+	flags.IntVar(&o.Sample, "sample", 0, "Run the floaters on this many nodes picked at random among the selected ones of every cluster, 0 disables it.")
+	flags.IntVar(&o.SamplePerZone, "sample-per-zone", 0, "Run the floaters on this many nodes picked at random in every topology.kubernetes.io/zone of every cluster, 0 disables it.")
+	flags.BoolVar(&o.EnableAnalysis, "enable-analysis", false, "Serve prometheus metrics of the mesh probes on /metrics of the floater port.")
 	flags.IntVarP(&o.PodWaitTime, "pod-wait-time", "w", 30, "Time for wait pod(floater) launch.")
-	flags.StringVar(&o.Protocol, "protocol", string(TCP), "Protocol for the network problem.")
+	flags.StringVar(&o.Protocol, "protocol", string(ICMP), "Protocol used to probe the targets, one of icmp, tcp, udp or http, mtu discovers the path MTU instead.")
 	flags.IntVar(&o.MaxNum, "max-num", 3, "Max number of go-route to lanuch.")
 	flags.BoolVar(&o.AutoClean, "auto-clean", false, "Auto clean the pods.")
+	flags.StringVar(&o.RunID, "run-id", "", "ID of the run whose floaters are used, defaults to the run of config.json when the same operator started it or to a new run, resume and clean always default to the run of config.json.")
 	flags.IntVar(&o.CmdTimeout, "cmd-timeout", 3, "Timeout for the command.")
+	flags.StringVar(&o.ProbeMode, "probe-mode", ProbeModeExec, "How the floaters run the probes, exec runs shell tools through an exec session, api calls the probe API of the floater through the pod proxy.")
+	flags.BoolVar(&o.Batch, "batch", false, "Send all targets of a source floater in a single exec session, where they are probed concurrently.")
+	flags.BoolVar(&o.Services, "services", false, "Also check the Service exported by the destination floaters through Kosmos MCS, by its imported IP and DNS name.")
+	flags.StringVar(&o.ClusterDomain, "cluster-domain", DefaultClusterDomain, "Cluster domain used to build the DNS name of the imported Service.")
+	flags.IntVar(&o.Count, "count", 1, "Number of echo requests sent to every target by the icmp probe.")
+	flags.Float64Var(&o.Interval, "interval", 1, "Seconds between the echo requests of the icmp probe.")
+	flags.IntVar(&o.PacketSize, "packet-size", 56, "Payload size in bytes of the echo requests of the icmp probe.")
+	flags.Float64Var(&o.LatencyThreshold, "latency-threshold", 0, "Average RTT in milliseconds above which a reachable target is reported as slow, 0 disables it.")
+	flags.Float64Var(&o.LossThreshold, "loss-threshold", 0, "Packet loss percentage above which a reachable target is reported as slow.")
+	flags.StringVarP(&o.Output, "output", "o", "", "Output format, one of wide, json, yaml, csv or junit.")
+	flags.StringVar(&o.View, "view", ViewList, "View of the table output, list prints one row per target, matrix prints a source node x destination node grid, cluster prints the cluster grid of a multi-cluster check only.")
+	flags.Float64Var(&o.FailThreshold, "fail-threshold", 0, "Tolerated percentage of failed checks, the command exits with 2 above it and with 3 if any probe hit an exception.")
 
 	return cmd, o
 }
 
-func NewCmdCheck() *cobra.Command {
-	cmd, _ := NewOptions()
+func NewCmdCheck(configFlags *genericclioptions.ConfigFlags) *cobra.Command {
+	cmd, _ := NewOptions(configFlags)
+	cmd.AddCommand(NewCmdCheckDNS(configFlags))
 	return cmd
 }
 
+// flagChanged reports whether the flag name was set on the command line.
+func (o *CommandCheckOptions) flagChanged(name string) bool {
+	return o.flags != nil && o.flags.Changed(name)
+}
+
+// clusterFlags select the checked clusters, setting any of them replaces the clusters of config.json.
+var clusterFlags = []string{"src-kubeconfig", "dst-kubeconfig", "src-context", "dst-context", "kubeconfig-list", "contexts", "all-clusters"}
+
+func (o *CommandCheckOptions) clustersChanged() bool {
+	for _, name := range clusterFlags {
+		if o.flagChanged(name) {
+			return true
+		}
+	}
+	return false
+}
+
 func (o *CommandCheckOptions) LoadConfig() {
 	fromConfig := &CommandCheckOptions{}
 	if err := util.ReadOpt(fromConfig); err == nil {
 		once.Do(func() {
 			klog.Infof("use config from file!!!!!!")
 		})
-		o.Namespace = fromConfig.Namespace
-		o.ImageRepository = fromConfig.ImageRepository
-		o.DstImageRepository = fromConfig.DstImageRepository
-		o.SrcKubeConfig = fromConfig.SrcKubeConfig
-		o.DstKubeConfig = fromConfig.DstKubeConfig
-		o.HostNetwork = fromConfig.HostNetwork
-		o.Port = fromConfig.Port
-		o.PodWaitTime = fromConfig.PodWaitTime
-		o.Protocol = fromConfig.Protocol
-		o.MaxNum = fromConfig.MaxNum
-		o.AutoClean = fromConfig.AutoClean
-		o.CmdTimeout = fromConfig.CmdTimeout
-		o.Version = fromConfig.Version
+		if len(fromConfig.Namespace) > 0 && !o.flagChanged("namespace") {
+			o.Namespace = fromConfig.Namespace
+		}
+		if len(fromConfig.ImageRepository) > 0 && !o.flagChanged("image-repository") {
+			o.ImageRepository = fromConfig.ImageRepository
+		}
+		if !o.flagChanged("dst-image-repository") {
+			o.DstImageRepository = fromConfig.DstImageRepository
+		}
+		if !o.flagChanged("host-network") {
+			o.HostNetwork = fromConfig.HostNetwork
+		}
+		if len(fromConfig.Port) > 0 && !o.flagChanged("port") {
+			o.Port = fromConfig.Port
+		}
+		if fromConfig.PodWaitTime > 0 && !o.flagChanged("pod-wait-time") {
+			o.PodWaitTime = fromConfig.PodWaitTime
+		}
+		if len(fromConfig.Protocol) > 0 && !o.flagChanged("protocol") {
+			o.Protocol = fromConfig.Protocol
+		}
+		if fromConfig.MaxNum > 0 && !o.flagChanged("max-num") {
+			o.MaxNum = fromConfig.MaxNum
+		}
+		if !o.flagChanged("auto-clean") {
+			o.AutoClean = fromConfig.AutoClean
+		}
+		if fromConfig.CmdTimeout > 0 && !o.flagChanged("cmd-timeout") {
+			o.CmdTimeout = fromConfig.CmdTimeout
+		}
+		if !o.flagChanged("mode") {
+			o.Mode = fromConfig.Mode
+		}
+		if len(fromConfig.ProbeMode) > 0 && !o.flagChanged("probe-mode") {
+			o.ProbeMode = fromConfig.ProbeMode
+		}
+		if !o.flagChanged("services") {
+			o.Services = fromConfig.Services
+		}
+		if len(fromConfig.ClusterDomain) > 0 && !o.flagChanged("cluster-domain") {
+			o.ClusterDomain = fromConfig.ClusterDomain
+		}
+		if !o.flagChanged("enable-analysis") {
+			o.EnableAnalysis = fromConfig.EnableAnalysis
+		}
+		if !o.flagChanged("namespaced") {
+			o.Namespaced = fromConfig.Namespaced
+		}
+		if !o.flagChanged("node-selector") {
+			o.NodeSelector = fromConfig.NodeSelector
+		}
+		if !o.flagChanged("nodes") {
+			o.Nodes = fromConfig.Nodes
+		}
+		if !o.flagChanged("exclude-nodes") {
+			o.ExcludeNodes = fromConfig.ExcludeNodes
+		}
+		// only one of sample and sample-per-zone is used, setting one replaces both
+		if !o.flagChanged("sample") && !o.flagChanged("sample-per-zone") {
+			o.Sample = fromConfig.Sample
+			o.SamplePerZone = fromConfig.SamplePerZone
+		}
+		// an explicit --probe-mode api drops the batch of the previous run, batch only runs exec sessions
+		if !o.flagChanged("batch") && !(o.flagChanged("probe-mode") && o.ProbeMode != ProbeModeExec) {
+			o.Batch = fromConfig.Batch
+		}
+		// the cluster object and the CIDRs map describe the saved destination cluster
+		if !o.flagChanged("dst-cluster-name") && !o.clustersChanged() {
+			o.DstClusterName = fromConfig.DstClusterName
+		}
+		if !o.flagChanged("cidrs-map") && !o.clustersChanged() {
+			o.CIDRsMap = fromConfig.CIDRsMap
+		}
+		if !o.clustersChanged() {
+			o.SrcKubeConfig = fromConfig.SrcKubeConfig
+			o.DstKubeConfig = fromConfig.DstKubeConfig
+			o.SrcContext = fromConfig.SrcContext
+			o.DstContext = fromConfig.DstContext
+			o.KubeConfigList = fromConfig.KubeConfigList
+			o.Contexts = fromConfig.Contexts
+			o.AllClusters = fromConfig.AllClusters
+		}
+		if !o.flagChanged("bidirectional") {
+			o.Bidirectional = fromConfig.Bidirectional
+		}
+		if !o.flagChanged("src-cluster-name") && !o.clustersChanged() {
+			o.SrcClusterName = fromConfig.SrcClusterName
+		}
+		if !o.flagChanged("src-cidrs-map") && !o.clustersChanged() {
+			o.SrcCIDRsMap = fromConfig.SrcCIDRsMap
+		}
+		if !o.flagChanged("fail-threshold") {
+			o.FailThreshold = fromConfig.FailThreshold
+		}
+		if fromConfig.Count > 0 && !o.flagChanged("count") {
+			o.Count = fromConfig.Count
+		}
+		if fromConfig.Interval > 0 && !o.flagChanged("interval") {
+			o.Interval = fromConfig.Interval
+		}
+		if fromConfig.PacketSize > 0 && !o.flagChanged("packet-size") {
+			o.PacketSize = fromConfig.PacketSize
+		}
+		if !o.flagChanged("latency-threshold") {
+			o.LatencyThreshold = fromConfig.LatencyThreshold
+		}
+		if !o.flagChanged("loss-threshold") {
+			o.LossThreshold = fromConfig.LossThreshold
+		}
+		// resume and clean continue the saved run, check only the run of its operator so that the floaters of
+		// several checks are cleaned at once, an explicit --run-id selects another run
+		if len(fromConfig.RunID) > 0 && len(o.RunID) == 0 && (o.reuseRun || fromConfig.Owner == RunOwner()) {
+			o.RunID = fromConfig.RunID
+			o.Owner = fromConfig.Owner
+		}
 	}
 }
 
 func (o *CommandCheckOptions) Complete() error {
 	// load config from config.json
 	o.LoadConfig()
+	o.completeRunID()
+
+	// the path MTU discovery outlasts the default timeout, only a timeout set explicitly is validated
+	if Protocol(o.Protocol) == MTU && !o.flagChanged("cmd-timeout") && o.CmdTimeout < command.MTUProbeTimeout {
+		o.CmdTimeout = command.MTUProbeTimeout
+	}
 
 	if len(o.DstImageRepository) == 0 {
 		o.DstImageRepository = o.ImageRepository
 	}
 
+	if o.MultiCluster() {
+		return o.completeMembers()
+	}
+
 	srcFloater := NewCheckFloater(o, false)
-	if err := srcFloater.completeFromKubeConfigPath(o.SrcKubeConfig); err != nil {
+	if err := srcFloater.completeFromClientConfig(newClientConfig(o.ConfigFlags, o.SrcKubeConfig, o.SrcContext, true)); err != nil {
 		return err
 	}
 	o.SrcFloater = srcFloater
 
-	if o.DstKubeConfig != "" {
+	if o.HasDst() {
 		dstFloater := NewCheckFloater(o, true)
-		if err := dstFloater.completeFromKubeConfigPath(o.DstKubeConfig); err != nil {
+		if err := dstFloater.completeFromClientConfig(o.dstClientConfig()); err != nil {
 			return err
 		}
 		o.DstFloater = dstFloater
+
+		if err := o.completeCIDRsMap(); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// HasDst reports whether a destination cluster is checked, it is selected by a kubeconfig file or a context.
+func (o *CommandCheckOptions) HasDst() bool {
+	return o.DstKubeConfig != "" || o.DstContext != ""
+}
+
+// dstClientConfig loads the destination cluster, a context without kubeconfig file is read from the
+// kubeconfig of the source cluster.
+func (o *CommandCheckOptions) dstClientConfig() clientcmd.ClientConfig {
+	kubeConfigPath := o.DstKubeConfig
+	if len(kubeConfigPath) == 0 {
+		kubeConfigPath = o.SrcKubeConfig
+	}
+	return newClientConfig(o.ConfigFlags, kubeConfigPath, o.DstContext, false)
+}
+
+// completeCIDRsMap fills the global CIDRs map of the destination floater, and of the source floater when the
+// checks run both ways.
+func (o *CommandCheckOptions) completeCIDRsMap() error {
+	cidrsMap, err := resolveCIDRsMap(o.DstClusterName, o.CIDRsMap, o.DstFloater, o.SrcFloater)
+	if err != nil {
+		return err
+	}
+	if len(cidrsMap) > 0 {
+		klog.Infof("map destination IPs with globalCIDRsMap: %v", cidrsMap)
+	}
+	o.DstFloater.CIDRsMap = cidrsMap
+
+	if o.Bidirectional {
+		cidrsMap, err = resolveCIDRsMap(o.SrcClusterName, o.SrcCIDRsMap, o.SrcFloater, o.DstFloater)
+		if err != nil {
+			return err
+		}
+		if len(cidrsMap) > 0 {
+			klog.Infof("map source IPs with globalCIDRsMap: %v", cidrsMap)
+		}
+		o.SrcFloater.CIDRsMap = cidrsMap
+	}
+
+	return nil
+}
+
+// resolveCIDRsMap reads the global CIDRs map of the cluster object clusterName, it is looked up in the cluster
+// of the floater owning the CIDRs first and then in the other cluster, the overrides take precedence.
+func resolveCIDRsMap(clusterName string, overrides map[string]string, owner, other *Floater) (map[string]string, error) {
+	cidrsMap := map[string]string{}
+
+	if len(clusterName) > 0 {
+		m, err := owner.GetClusterCIDRsMap(clusterName)
+		if apierrors.IsNotFound(err) {
+			m, err = other.GetClusterCIDRsMap(clusterName)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("get globalCIDRsMap of cluster %s failed: %v", clusterName, err)
+		}
+		for src, dst := range m {
+			cidrsMap[src] = dst
+		}
+	}
+
+	for src, dst := range overrides {
+		cidrsMap[src] = dst
+	}
+
+	return cidrsMap, nil
+}
+
 func (o *CommandCheckOptions) Validate() error {
 	if len(o.Namespace) == 0 {
 		return fmt.Errorf("namespace must be specified")
 	}
 
-	return nil
+	for src, dst := range o.CIDRsMap {
+		if _, _, err := net.ParseCIDR(src); err != nil {
+			return fmt.Errorf("invalid cidrs-map entry %s=%s: %v", src, dst, err)
+		}
+		if _, _, err := net.ParseCIDR(dst); err != nil {
+			return fmt.Errorf("invalid cidrs-map entry %s=%s: %v", src, dst, err)
+		}
+	}
+	for src, dst := range o.SrcCIDRsMap {
+		if _, _, err := net.ParseCIDR(src); err != nil {
+			return fmt.Errorf("invalid src-cidrs-map entry %s=%s: %v", src, dst, err)
+		}
+		if _, _, err := net.ParseCIDR(dst); err != nil {
+			return fmt.Errorf("invalid src-cidrs-map entry %s=%s: %v", src, dst, err)
+		}
+	}
+	if o.Bidirectional && !o.HasDst() {
+		return fmt.Errorf("bidirectional checks run across clusters, dst-kubeconfig or dst-context must be specified")
+	}
+	if o.Sample < 0 || o.SamplePerZone < 0 {
+		return fmt.Errorf("sample and sample-per-zone must not be negative")
+	}
+	if o.Sample > 0 && o.SamplePerZone > 0 {
+		return fmt.Errorf("only one of sample and sample-per-zone can be used")
+	}
+	if o.Namespaced {
+		if o.SamplePerZone > 0 {
+			return fmt.Errorf("sample-per-zone reads the zones of the Node objects, it can not be used with namespaced")
+		}
+		if o.AllClusters {
+			return fmt.Errorf("all-clusters reads the cluster-wide Kosmos cluster objects, it can not be used with namespaced")
+		}
+		if len(o.DstClusterName) > 0 || len(o.SrcClusterName) > 0 {
+			return fmt.Errorf("the Kosmos cluster objects are cluster-wide, use cidrs-map and src-cidrs-map instead of dst-cluster-name and src-cluster-name with namespaced")
+		}
+	}
+
+	if !IsSupportedProtocol(o.Protocol) {
+		return fmt.Errorf("protocol %q is not supported, must be one of %v", o.Protocol, SupportedProtocols)
+	}
+
+	if o.Count < 1 || o.Interval <= 0 || o.PacketSize < 0 {
+		return fmt.Errorf("count must be at least 1, interval must be positive and packet-size must not be negative")
+	}
+	// the whole ping has to finish before the exec session times out
+	isPing := Protocol(o.Protocol) == ICMP || Protocol(o.Protocol) == IPv4
+	if cmdTimeout := o.SrcFloater.GetCmdTimeout().Seconds(); isPing && float64(o.Count-1)*o.Interval+1 >= cmdTimeout {
+		return fmt.Errorf("cmd-timeout %vs is too short to send %d echo requests every %vs", cmdTimeout, o.Count, o.Interval)
+	}
+	if cmdTimeout := o.SrcFloater.GetCmdTimeout().Seconds(); Protocol(o.Protocol) == MTU && cmdTimeout < command.MTUProbeTimeout {
+		return fmt.Errorf("cmd-timeout must be at least %ds for the path MTU discovery", command.MTUProbeTimeout)
+	}
+
+	if len(o.Mode) > 0 && !IsSupportedMode(o.Mode) {
+		return fmt.Errorf("mode %q is not supported, must be one of %v", o.Mode, SupportedModes)
+	}
+
+	if o.ProbeMode != ProbeModeExec && o.ProbeMode != ProbeModeAPI {
+		return fmt.Errorf("probe-mode %q is not supported, must be one of %s or %s", o.ProbeMode, ProbeModeExec, ProbeModeAPI)
+	}
+	if o.Services && !o.HasDst() {
+		return fmt.Errorf("services can only be checked across clusters, dst-kubeconfig or dst-context must be specified")
+	}
+
+	if o.Batch && o.ProbeMode != ProbeModeExec {
+		return fmt.Errorf("batch can only be used with probe-mode %s", ProbeModeExec)
+	}
+
+	if o.FailThreshold < 0 || o.FailThreshold > 100 {
+		return fmt.Errorf("fail-threshold must be a percentage between 0 and 100, got %v", o.FailThreshold)
+	}
+
+	if !IsSupportedOutput(o.Output) {
+		return fmt.Errorf("output %q is not supported, must be one of %v", o.Output, SupportedOutputs)
+	}
+
+	if o.View != ViewList && o.View != ViewMatrix && o.View != ViewCluster {
+		return fmt.Errorf("view %q is not supported, must be one of %s, %s or %s", o.View, ViewList, ViewMatrix, ViewCluster)
+	}
+	if o.View != ViewList && IsStructuredOutput(o.Output) {
+		return fmt.Errorf("view %s can not be used with output %s", o.View, o.Output)
+	}
+
+	return o.validateMultiCluster()
 }
 
 func (o *CommandCheckOptions) Clean() error {
+	if o.MultiCluster() {
+		for _, member := range o.Members {
+			if err := member.Floater.RemoveFloater(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	if err := o.SrcFloater.RemoveFloater(); err != nil {
 		return err
 	}
 
-	if o.DstKubeConfig != "" {
+	if o.HasDst() {
 		if err := o.DstFloater.RemoveFloater(); err != nil {
 			return err
 		}
@@ -186,74 +673,174 @@ func (o *CommandCheckOptions) Clean() error {
 }
 
 func (o *CommandCheckOptions) Run() error {
-	var resultData []*PrintCheckData
+	if err := o.Preflight(false); err != nil {
+		return err
+	}
 
-	if err := o.SrcFloater.CreateFloater(); err != nil {
+	var resultData []*PrintCheckData
+	var err error
+	if o.MultiCluster() {
+		resultData, err = o.RunMultiCluster()
+	} else {
+		resultData, err = o.RunSrcDst()
+	}
+	if err != nil {
 		return err
 	}
 
-	if o.DstKubeConfig != "" {
-		if o.DstFloater.EnableHostNetwork {
-			srcNodeInfos, err := o.SrcFloater.GetNodesInfo()
-			if err != nil {
-				return fmt.Errorf("get src cluster nodeInfos failed: %s", err)
-			}
+	o.PrintResult(resultData)
 
-			if err = o.DstFloater.CreateFloater(); err != nil {
-				return err
-			}
-			var dstNodeInfos []*FloatInfo
-			dstNodeInfos, err = o.DstFloater.GetNodesInfo()
-			if err != nil {
-				return fmt.Errorf("get dist cluster nodeInfos failed: %s", err)
-			}
+	if o.AutoClean {
+		if err = o.Clean(); err != nil {
+			return err
+		}
+	}
 
-			resultData = o.RunNative(srcNodeInfos, dstNodeInfos)
-		} else {
-			srcPodInfos, err := o.SrcFloater.GetPodInfo()
-			if err != nil {
-				return fmt.Errorf("get src cluster podInfos failed: %s", err)
-			}
+	// save options for resume
+	o.SaveOpts()
 
-			if err = o.DstFloater.CreateFloater(); err != nil {
-				return err
-			}
-			var dstPodInfos []*FloatInfo
-			dstPodInfos, err = o.DstFloater.GetPodInfo()
-			if err != nil {
-				return fmt.Errorf("get dist cluster podInfos failed: %s", err)
-			}
+	return NewCheckSummary(resultData, o.Warning).ExitError(o.FailThreshold)
+}
 
-			resultData = o.RunRange(srcPodInfos, dstPodInfos)
+// RunSrcDst checks the pairs of modes from the source cluster to the destination cluster, or inside the
+// source cluster if there is no destination.
+func (o *CommandCheckOptions) RunSrcDst() ([]*PrintCheckData, error) {
+	var resultData []*PrintCheckData
+
+	modes := o.checkModes()
+	var srcNetworks, dstNetworks []bool
+	for _, mode := range modes {
+		srcHost, dstHost := mode.hostNetworks()
+		srcNetworks = append(srcNetworks, srcHost)
+		dstNetworks = append(dstNetworks, dstHost)
+	}
+	// the services are checked between the floaters of --host-network
+	if o.Services {
+		srcNetworks = append(srcNetworks, o.SrcFloater.EnableHostNetwork)
+		dstNetworks = append(dstNetworks, o.DstFloater.EnableHostNetwork)
+	}
+	if !o.HasDst() {
+		srcNetworks = append(srcNetworks, dstNetworks...)
+	}
+
+	srcInfos, err := prepareFloaters(o.SrcFloater, "src", srcNetworks)
+	if err != nil {
+		return nil, err
+	}
+	dstInfos := srcInfos
+	if o.HasDst() {
+		if dstInfos, err = prepareFloaters(o.DstFloater, "dist", dstNetworks); err != nil {
+			return nil, err
 		}
-	} else {
-		if o.SrcFloater.EnableHostNetwork {
-			srcNodeInfos, err := o.SrcFloater.GetNodesInfo()
+	}
+
+	forward := *o
+	if o.Bidirectional {
+		forward.scope.Direction = DirectionForward
+	}
+	for _, mode := range modes {
+		srcHost, dstHost := mode.hostNetworks()
+		resultData = append(resultData, forward.runMode(mode, srcInfos[srcHost], dstInfos[dstHost])...)
+	}
+	if o.Bidirectional {
+		reverse := o.reverse()
+		reverse.scope.Direction = DirectionReverse
+		for _, mode := range modes {
+			srcHost, dstHost := mode.hostNetworks()
+			resultData = append(resultData, reverse.runMode(mode.reverse(), dstInfos[dstHost], srcInfos[srcHost])...)
+		}
+	}
+
+	if o.Services {
+		serviceData, err := o.RunServices()
+		if err != nil {
+			return nil, fmt.Errorf("check floater service failed: %s", err)
+		}
+		resultData = append(resultData, serviceData...)
+	}
+
+	return resultData, nil
+}
+
+// checkModes expands --mode, --host-network picks the mode when it is not set.
+func (o *CommandCheckOptions) checkModes() []CheckMode {
+	switch CheckMode(o.Mode) {
+	case "":
+		if o.HostNetwork {
+			return []CheckMode{ModeNodeNode}
+		}
+		return []CheckMode{ModePodPod}
+	case ModeAll:
+		return []CheckMode{ModePodPod, ModeNodeNode, ModePodNode, ModeNodePod}
+	default:
+		return []CheckMode{CheckMode(o.Mode)}
+	}
+}
+
+// runMode probes the floaters jInfos in the destination network of mode from the floaters iInfos.
+func (o *CommandCheckOptions) runMode(mode CheckMode, iInfos []*FloatInfo, jInfos []*FloatInfo) []*PrintCheckData {
+	scoped := *o
+	scoped.scope.Mode = string(mode)
+	if _, dstHost := mode.hostNetworks(); dstHost {
+		return scoped.RunNative(iInfos, jInfos)
+	}
+	return scoped.RunRange(iInfos, jInfos)
+}
+
+// prepareFloaters starts the floaters of the cluster of f in the host and in the pod network as required
+// by hostNetworks, and returns the floaters of every network. The node IPs are filled for the host network.
+func prepareFloaters(f *Floater, cluster string, hostNetworks []bool) (map[bool][]*FloatInfo, error) {
+	infos := map[bool][]*FloatInfo{}
+	for _, hostNetwork := range hostNetworks {
+		if _, ok := infos[hostNetwork]; ok {
+			continue
+		}
+		floater := f.WithHostNetwork(hostNetwork)
+		if err := floater.CreateFloater(); err != nil {
+			return nil, err
+		}
+
+		if hostNetwork {
+			nodeInfos, err := floater.GetNodesInfo()
 			if err != nil {
-				return fmt.Errorf("get src cluster nodeInfos failed: %s", err)
+				return nil, fmt.Errorf("get %s cluster nodeInfos failed: %s", cluster, err)
 			}
-			resultData = o.RunNative(srcNodeInfos, srcNodeInfos)
+			infos[hostNetwork] = nodeInfos
 		} else {
-			srcPodInfos, err := o.SrcFloater.GetPodInfo()
+			podInfos, err := floater.GetPodInfo()
 			if err != nil {
-				return fmt.Errorf("get src cluster podInfos failed: %s", err)
+				return nil, fmt.Errorf("get %s cluster podInfos failed: %s", cluster, err)
 			}
-			resultData = o.RunRange(srcPodInfos, srcPodInfos)
+			infos[hostNetwork] = podInfos
 		}
 	}
+	return infos, nil
+}
 
-	o.PrintResult(resultData)
+const (
+	WarningSlow   = "SLOW"
+	WarningLowMTU = "LOW_MTU"
+)
 
-	if o.AutoClean {
-		if err := o.Clean(); err != nil {
-			return err
-		}
+// Warning flags a reachable target that exceeds the latency or the packet loss threshold, or whose
+// path MTU is below the MTU of the interface sending to it.
+func (o *CommandCheckOptions) Warning(r *PrintCheckData) string {
+	if r.Status != command.CommandSuccessed {
+		return ""
 	}
-
-	// save options for resume
-	o.SaveOpts()
-
-	return nil
+	if r.MTU != nil && r.MTU.PathMTU < r.MTU.InterfaceMTU {
+		return WarningLowMTU
+	}
+	if r.Statistics == nil {
+		return ""
+	}
+	if o.LatencyThreshold > 0 && r.Statistics.Avg > o.LatencyThreshold {
+		return WarningSlow
+	}
+	if r.Statistics.Loss > o.LossThreshold {
+		return WarningSlow
+	}
+	return ""
 }
 
 func (o *CommandCheckOptions) SaveOpts() {
@@ -271,112 +858,179 @@ func (o *CommandCheckOptions) Skip(podInfo *FloatInfo, targetIP string) bool {
 	}
 	// is resume: filt
 	for _, r := range o.ResumeRecord {
-		if r.SrcNodeName == podInfo.NodeName && r.TargetIP == targetIP {
+		if r.SrcNodeName == podInfo.NodeName && r.TargetIP == targetIP && r.scope() == o.scope {
 			return false
 		}
 	}
 	return true
 }
 
-func (o *CommandCheckOptions) RunRange(iPodInfos []*FloatInfo, jPodInfos []*FloatInfo) []*PrintCheckData {
-	var resultData []*PrintCheckData
-	mutex := sync.Mutex{}
+func (o *CommandCheckOptions) newBar(max int) *progressbar.ProgressBar {
+	if IsStructuredOutput(o.Output) {
+		return utils.NewStderrBar(max)
+	}
+	return utils.NewBar(max)
+}
 
-	barctl := utils.NewBar(len(jPodInfos) * len(iPodInfos))
-
-	worker := func(iPodInfo *FloatInfo) {
-		for _, jPodInfo := range jPodInfos {
-			for _, ip := range jPodInfo.PodIPs {
-				var targetIP string
-				var err error
-				var cmdResult *command.Result
-				if o.DstFloater != nil {
-					targetIP, err = netmap.NetMap(ip, o.DstFloater.CIDRsMap)
-				} else {
-					targetIP = ip
-				}
-				if err != nil {
-					cmdResult = command.ParseError(err)
-				} else {
-					// isSkip
-					if o.Skip(iPodInfo, targetIP) {
-						continue
-					}
-					// ToDo RunRange && RunNative func support multiple commands, and the code needs to be optimized
-					cmdObj := &command.Ping{
-						TargetIP: targetIP,
-					}
-					cmdResult = o.SrcFloater.CommandExec(iPodInfo, cmdObj)
-				}
-				mutex.Lock()
-				resultData = append(resultData, &PrintCheckData{
-					*cmdResult,
-					iPodInfo.NodeName, jPodInfo.NodeName, targetIP,
-				})
-				mutex.Unlock()
-			}
-			barctl.Add(1)
-		}
+// checkTarget is one IP of a destination node, err is set if the IP could not be mapped.
+type checkTarget struct {
+	DstNodeName string
+	TargetIP    string
+	Protocol    Protocol
+	// DNSServer and Expect are only set for dns targets, whose TargetIP is the name to resolve
+	DNSServer string
+	Expect    string
+	err       error
+}
+
+func (o *CommandCheckOptions) probeOptions() ProbeOptions {
+	return ProbeOptions{
+		Port:       o.Port,
+		Timeout:    o.SrcFloater.GetProbeTimeout(),
+		Count:      o.Count,
+		Interval:   o.Interval,
+		PacketSize: o.PacketSize,
 	}
+}
 
-	var wg sync.WaitGroup
-	ch := make(chan struct{}, o.MaxNum)
+func (o *CommandCheckOptions) targetProbeOptions(target checkTarget) ProbeOptions {
+	opts := o.probeOptions()
+	opts.DNSServer = target.DNSServer
+	opts.Expect = target.Expect
+	return opts
+}
 
-	if len(iPodInfos) > 0 && len(jPodInfos) > 0 {
-		for _, iPodInfo := range iPodInfos {
-			podInfo := iPodInfo
-			ch <- struct{}{}
-			wg.Add(1)
-			go func() {
-				defer wg.Done()
-				worker(podInfo)
-				<-ch
-			}()
-		}
+func (o *CommandCheckOptions) exec(fInfo *FloatInfo, target checkTarget) *command.Result {
+	opts := o.targetProbeOptions(target)
+	if o.ProbeMode == ProbeModeAPI {
+		return o.SrcFloater.CommandProxy(fInfo, NewProbeRequest(target.Protocol, target.TargetIP, opts))
 	}
 
-	wg.Wait()
+	cmdObj, err := NewCommand(target.Protocol, target.TargetIP, opts)
+	if err != nil {
+		return command.ParseError(err)
+	}
+	return o.SrcFloater.CommandExec(fInfo, cmdObj)
+}
 
-	return resultData
+// execBatch probes all targets from fInfo in one exec session.
+func (o *CommandCheckOptions) execBatch(fInfo *FloatInfo, targets []checkTarget) []*command.Result {
+	results := make([]*command.Result, len(targets))
+	var cmds []command.Command
+	var indexes []int
+	for i, target := range targets {
+		cmdObj, err := NewCommand(target.Protocol, target.TargetIP, o.targetProbeOptions(target))
+		if err != nil {
+			results[i] = command.ParseError(err)
+			continue
+		}
+		cmds = append(cmds, cmdObj)
+		indexes = append(indexes, i)
+	}
+	for i, result := range o.SrcFloater.BatchCommandExec(fInfo, cmds) {
+		results[indexes[i]] = result
+	}
+	return results
+}
+
+func (o *CommandCheckOptions) RunRange(iPodInfos []*FloatInfo, jPodInfos []*FloatInfo) []*PrintCheckData {
+	iPodInfos, jPodInfos = o.selectPairs(iPodInfos, jPodInfos)
+	var targets []checkTarget
+	for _, jPodInfo := range jPodInfos {
+		for _, ip := range jPodInfo.PodIPs {
+			target := checkTarget{DstNodeName: jPodInfo.NodeName, TargetIP: ip, Protocol: Protocol(o.Protocol)}
+			if o.DstFloater != nil {
+				target.TargetIP, target.err = netmap.NetMap(ip, o.DstFloater.CIDRsMap)
+			}
+			targets = append(targets, target)
+		}
+	}
+	return o.runTargets(iPodInfos, targets)
 }
 
 func (o *CommandCheckOptions) RunNative(iNodeInfos []*FloatInfo, jNodeInfos []*FloatInfo) []*PrintCheckData {
+	iNodeInfos, jNodeInfos = o.selectPairs(iNodeInfos, jNodeInfos)
+	var targets []checkTarget
+	for _, jNodeInfo := range jNodeInfos {
+		for _, ip := range jNodeInfo.NodeIPs {
+			targets = append(targets, checkTarget{DstNodeName: jNodeInfo.NodeName, TargetIP: ip, Protocol: Protocol(o.Protocol)})
+		}
+	}
+	return o.runTargets(iNodeInfos, targets)
+}
+
+// runTargets probes every target from every floater in iInfos, at most MaxNum floaters at a time.
+func (o *CommandCheckOptions) runTargets(iInfos []*FloatInfo, targets []checkTarget) []*PrintCheckData {
 	var resultData []*PrintCheckData
+	mutex := sync.Mutex{}
 
-	barctl := utils.NewBar(len(iNodeInfos) * len(jNodeInfos))
+	barctl := o.newBar(len(iInfos) * len(targets))
 
-	worker := func(iNodeInfo *FloatInfo) {
-		for _, jNodeInfo := range jNodeInfos {
-			for _, ip := range jNodeInfo.NodeIPs {
-				// isSkip
-				if o.Skip(iNodeInfo, ip) {
-					continue
-				}
-				// ToDo RunRange && RunNative func support multiple commands, and the code needs to be optimized
-				cmdObj := &command.Ping{
-					TargetIP: ip,
-				}
-				cmdResult := o.SrcFloater.CommandExec(iNodeInfo, cmdObj)
-				resultData = append(resultData, &PrintCheckData{
-					*cmdResult,
-					iNodeInfo.NodeName, jNodeInfo.NodeName, ip,
-				})
+	worker := func(iInfo *FloatInfo) {
+		var pending []checkTarget
+		var results []*command.Result
+		// indexes of the pending targets left to the batch exec session
+		var batched []int
+		for _, target := range targets {
+			if target.err != nil {
+				pending = append(pending, target)
+				results = append(results, command.ParseError(target.err))
+				barctl.Add(1)
+				continue
 			}
+			// isSkip
+			if o.Skip(iInfo, target.TargetIP) {
+				barctl.Add(1)
+				continue
+			}
+			pending = append(pending, target)
+			if o.Batch {
+				batched = append(batched, len(results))
+				results = append(results, nil)
+				continue
+			}
+			results = append(results, o.exec(iInfo, target))
 			barctl.Add(1)
 		}
+
+		if len(batched) > 0 {
+			batchTargets := make([]checkTarget, 0, len(batched))
+			for _, i := range batched {
+				batchTargets = append(batchTargets, pending[i])
+			}
+			for i, result := range o.execBatch(iInfo, batchTargets) {
+				results[batched[i]] = result
+			}
+			barctl.Add(len(batched))
+		}
+
+		mutex.Lock()
+		for i, target := range pending {
+			resultData = append(resultData, &PrintCheckData{
+				Result:      *results[i],
+				SrcNodeName: iInfo.NodeName,
+				DstNodeName: target.DstNodeName,
+				TargetIP:    target.TargetIP,
+				SrcCluster:  o.scope.SrcCluster,
+				DstCluster:  o.scope.DstCluster,
+				Mode:        o.scope.Mode,
+				Direction:   o.scope.Direction,
+			})
+		}
+		mutex.Unlock()
 	}
 
 	var wg sync.WaitGroup
 	ch := make(chan struct{}, o.MaxNum)
 
-	if len(iNodeInfos) > 0 && len(jNodeInfos) > 0 {
-		for _, iNodeInfo := range iNodeInfos {
-			nodeInfo := iNodeInfo
+	if len(iInfos) > 0 && len(targets) > 0 {
+		for _, iInfo := range iInfos {
+			info := iInfo
 			ch <- struct{}{}
 			wg.Add(1)
 			go func() {
 				defer wg.Done()
-				worker(nodeInfo)
+				worker(info)
 				<-ch
 			}()
 		}
@@ -386,55 +1040,3 @@ func (o *CommandCheckOptions) RunNative(iNodeInfos []*FloatInfo, jNodeInfos []*F
 
 	return resultData
 }
-
-func (o *CommandCheckOptions) PrintResult(resultData []*PrintCheckData) {
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"S/N", "SRC_NODE_NAME", "DST_NODE_NAME", "TARGET_IP", "RESULT"})
-
-	tableException := tablewriter.NewWriter(os.Stdout)
-	tableException.SetHeader([]string{"S/N", "SRC_NODE_NAME", "DST_NODE_NAME", "TARGET_IP", "RESULT", "LOG"})
-
-	tableFailed := tablewriter.NewWriter(os.Stdout)
-	tableFailed.SetHeader([]string{"S/N", "SRC_NODE_NAME", "DST_NODE_NAME", "TARGET_IP", "RESULT", "LOG"})
-
-	resumeData := []*PrintCheckData{}
-
-	for index, r := range resultData {
-		// klog.Infof(fmt.Sprintf("%s %s %v", r.SrcNodeName, r.DstNodeName, r.IsSucceed))
-		row := []string{strconv.Itoa(index + 1), r.SrcNodeName, r.DstNodeName, r.TargetIP, command.PrintStatus(r.Status), r.ResultStr}
-		if r.Status == command.CommandFailed {
-			resumeData = append(resumeData, r)
-			tableFailed.Rich(row, []tablewriter.Colors{
-				{},
-				{tablewriter.Bold, tablewriter.FgHiRedColor},
-				{tablewriter.Bold, tablewriter.FgHiRedColor},
-				{tablewriter.Bold, tablewriter.FgHiRedColor},
-				{tablewriter.Bold, tablewriter.FgHiRedColor},
-			})
-		} else if r.Status == command.ExecError {
-			resumeData = append(resumeData, r)
-			tableException.Rich(row, []tablewriter.Colors{
-				{},
-				{tablewriter.Bold, tablewriter.FgCyanColor},
-				{tablewriter.Bold, tablewriter.FgCyanColor},
-				{tablewriter.Bold, tablewriter.FgCyanColor},
-				{tablewriter.Bold, tablewriter.FgCyanColor},
-			})
-		} else {
-			// resumeData = append(resumeData, r)
-			table.Rich(row[:len(row)-1], []tablewriter.Colors{
-				{},
-				{tablewriter.Bold, tablewriter.FgGreenColor},
-				{tablewriter.Bold, tablewriter.FgGreenColor},
-				{tablewriter.Bold, tablewriter.FgGreenColor},
-				{tablewriter.Bold, tablewriter.FgGreenColor},
-			})
-		}
-	}
-	fmt.Println("")
-	table.Render()
-	fmt.Println("")
-	tableException.Render()
-
-	util.WriteResume(resumeData)
-}
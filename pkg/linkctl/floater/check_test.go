@@ -0,0 +1,399 @@
+package floater
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/kosmos.io/linkctl/pkg/linkctl/util"
+)
+
+// useConfig runs the test in an empty directory, where saved is the config.json of a previous run.
+func useConfig(t *testing.T, saved *CommandCheckOptions) {
+	t.Helper()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(wd)
+	})
+
+	if saved != nil {
+		if err = util.WriteOpt(saved); err != nil {
+			t.Fatalf("write config.json failed: %v", err)
+		}
+	}
+}
+
+// loadOptions parses args and loads the options saved in config.json by a previous run.
+func loadOptions(t *testing.T, saved *CommandCheckOptions, args ...string) *CommandCheckOptions {
+	t.Helper()
+	useConfig(t, saved)
+
+	cmd, o := NewOptions(nil)
+	if err := cmd.Flags().Parse(args); err != nil {
+		t.Fatalf("parse %v failed: %v", args, err)
+	}
+	o.LoadConfig()
+	return o
+}
+
+// savedDefaults returns the options init and every run write to config.json when no flag is set.
+func savedDefaults(t *testing.T) *CommandCheckOptions {
+	t.Helper()
+	_, o := NewOptions(nil)
+	return o
+}
+
+func TestLoadConfigCommonOptions(t *testing.T) {
+	saved := savedDefaults(t)
+	saved.Namespace = "kosmos-test"
+	saved.Port = "8890"
+	saved.Protocol = string(TCP)
+	saved.AutoClean = true
+
+	o := loadOptions(t, saved)
+	if o.Namespace != "kosmos-test" || o.Port != "8890" || o.Protocol != string(TCP) || !o.AutoClean {
+		t.Errorf("namespace = %s, port = %s, protocol = %s, auto-clean = %v, want config.json", o.Namespace, o.Port, o.Protocol, o.AutoClean)
+	}
+	o = loadOptions(t, saved, "-n", "kosmos-system", "--port", "8889", "--protocol", string(ICMP), "--auto-clean=false")
+	if o.Namespace != "kosmos-system" || o.Port != "8889" || o.Protocol != string(ICMP) || o.AutoClean {
+		t.Errorf("namespace = %s, port = %s, protocol = %s, auto-clean = %v, want the flags", o.Namespace, o.Port, o.Protocol, o.AutoClean)
+	}
+}
+
+// TestLoadConfigVersion checks that the floater image of an older linkctl left in config.json is replaced by
+// the image of this one.
+func TestLoadConfigVersion(t *testing.T) {
+	useConfig(t, nil)
+	if err := os.WriteFile("config.json", []byte(`{"namespace":"kosmos-test","version":"v0.1.0"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, o := NewOptions(nil)
+	want := o.Version
+	o.LoadConfig()
+	if o.Namespace != "kosmos-test" || o.Version != want {
+		t.Errorf("namespace = %s, version = %s, want config.json and version %s", o.Namespace, o.Version, want)
+	}
+
+	o.SaveOpts()
+	data, err := os.ReadFile("config.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), `"version"`) {
+		t.Errorf("config.json saves the version: %s", data)
+	}
+}
+
+func TestLoadConfigPingOptions(t *testing.T) {
+	o := loadOptions(t, savedDefaults(t), "--count", "10", "--interval", "0.2", "--packet-size", "1400", "--loss-threshold", "5")
+	if o.Count != 10 || o.Interval != 0.2 || o.PacketSize != 1400 || o.LossThreshold != 5 {
+		t.Errorf("explicit flags were overridden by config.json: count %d, interval %v, packet-size %d, loss-threshold %v",
+			o.Count, o.Interval, o.PacketSize, o.LossThreshold)
+	}
+
+	saved := savedDefaults(t)
+	saved.Count = 5
+	saved.LatencyThreshold = 20
+	o = loadOptions(t, saved)
+	if o.Count != 5 || o.LatencyThreshold != 20 {
+		t.Errorf("unset flags did not inherit config.json: count %d, latency-threshold %v", o.Count, o.LatencyThreshold)
+	}
+
+	// a config.json of an older linkctl has no ping options
+	o = loadOptions(t, &CommandCheckOptions{Namespace: "kosmos-system"})
+	if o.Count != 1 || o.Interval != 1 || o.PacketSize != 56 {
+		t.Errorf("missing options did not keep the defaults: count %d, interval %v, packet-size %d", o.Count, o.Interval, o.PacketSize)
+	}
+}
+
+func TestLoadConfigProbeMode(t *testing.T) {
+	o := loadOptions(t, savedDefaults(t), "--probe-mode", ProbeModeAPI)
+	if o.ProbeMode != ProbeModeAPI {
+		t.Errorf("probe-mode = %s, want %s", o.ProbeMode, ProbeModeAPI)
+	}
+
+	saved := savedDefaults(t)
+	saved.ProbeMode = ProbeModeAPI
+	if o = loadOptions(t, saved); o.ProbeMode != ProbeModeAPI {
+		t.Errorf("probe-mode = %s, want %s from config.json", o.ProbeMode, ProbeModeAPI)
+	}
+}
+
+func TestLoadConfigMode(t *testing.T) {
+	saved := savedDefaults(t)
+	saved.Mode = string(ModeAll)
+	if o := loadOptions(t, saved, "--mode", string(ModePodNode)); o.Mode != string(ModePodNode) {
+		t.Errorf("mode = %s, want %s", o.Mode, ModePodNode)
+	}
+	if o := loadOptions(t, saved); o.Mode != string(ModeAll) {
+		t.Errorf("mode = %s, want %s from config.json", o.Mode, ModeAll)
+	}
+}
+
+func TestLoadConfigBatch(t *testing.T) {
+	saved := savedDefaults(t)
+	saved.Batch = true
+
+	tests := []struct {
+		name string
+		args []string
+		want bool
+	}{
+		{name: "inherited", want: true},
+		{name: "turned off", args: []string{"--batch=false"}},
+		{name: "probe api", args: []string{"--probe-mode", ProbeModeAPI}},
+		{name: "probe exec", args: []string{"--probe-mode", ProbeModeExec}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if o := loadOptions(t, saved, tt.args...); o.Batch != tt.want {
+				t.Errorf("batch = %v, want %v", o.Batch, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigServices(t *testing.T) {
+	saved := savedDefaults(t)
+	saved.Services = true
+	saved.ClusterDomain = "cluster.example"
+
+	o := loadOptions(t, saved, "--services=false", "--cluster-domain", "cluster.local")
+	if o.Services || o.ClusterDomain != "cluster.local" {
+		t.Errorf("services = %v, cluster-domain = %s, want the flags", o.Services, o.ClusterDomain)
+	}
+	o = loadOptions(t, saved)
+	if !o.Services || o.ClusterDomain != "cluster.example" {
+		t.Errorf("services = %v, cluster-domain = %s, want config.json", o.Services, o.ClusterDomain)
+	}
+}
+
+func TestLoadConfigBidirectional(t *testing.T) {
+	saved := savedDefaults(t)
+	saved.Bidirectional = true
+	saved.SrcClusterName = "cluster-a"
+
+	o := loadOptions(t, saved, "--bidirectional=false", "--src-cluster-name", "cluster-b")
+	if o.Bidirectional || o.SrcClusterName != "cluster-b" {
+		t.Errorf("bidirectional = %v, src-cluster-name = %s, want the flags", o.Bidirectional, o.SrcClusterName)
+	}
+	o = loadOptions(t, saved)
+	if !o.Bidirectional || o.SrcClusterName != "cluster-a" {
+		t.Errorf("bidirectional = %v, src-cluster-name = %s, want config.json", o.Bidirectional, o.SrcClusterName)
+	}
+	o = loadOptions(t, saved, "--src-kubeconfig", "/kube-config/c")
+	if o.SrcClusterName != "" {
+		t.Errorf("src-cluster-name = %s, want none for another source", o.SrcClusterName)
+	}
+}
+
+func TestLoadConfigClusterList(t *testing.T) {
+	saved := savedDefaults(t)
+	saved.KubeConfigList = []string{"/kube-config/a", "/kube-config/b"}
+
+	if o := loadOptions(t, saved); len(o.KubeConfigList) != 2 || !o.MultiCluster() {
+		t.Errorf("kubeconfig-list = %v, want config.json", o.KubeConfigList)
+	}
+	if o := loadOptions(t, saved, "--src-kubeconfig", "/kube-config/x"); o.MultiCluster() {
+		t.Errorf("kubeconfig-list = %v, contexts = %v, want a single cluster check", o.KubeConfigList, o.Contexts)
+	}
+	if o := loadOptions(t, saved, "--contexts", "ctx-a,ctx-b"); len(o.KubeConfigList) > 0 || len(o.Contexts) != 2 {
+		t.Errorf("kubeconfig-list = %v, contexts = %v, want the contexts only", o.KubeConfigList, o.Contexts)
+	}
+}
+
+func TestLoadConfigAllClusters(t *testing.T) {
+	saved := savedDefaults(t)
+	saved.AllClusters = true
+
+	if o := loadOptions(t, saved); !o.AllClusters {
+		t.Error("all-clusters was not read from config.json")
+	}
+	if o := loadOptions(t, saved, "--all-clusters=false"); o.AllClusters {
+		t.Error("all-clusters=false was overridden by config.json")
+	}
+	if o := loadOptions(t, saved, "--kubeconfig-list", "/kube-config/a,/kube-config/b"); o.AllClusters {
+		t.Error("kubeconfig-list did not replace the all-clusters of config.json")
+	}
+}
+
+func TestLoadConfigNamespaced(t *testing.T) {
+	saved := savedDefaults(t)
+	saved.Namespaced = true
+
+	if o := loadOptions(t, saved); !o.Namespaced {
+		t.Error("namespaced was not read from config.json")
+	}
+	if o := loadOptions(t, saved, "--namespaced=false"); o.Namespaced {
+		t.Error("namespaced=false was overridden by config.json")
+	}
+}
+
+func TestLoadConfigSrcDst(t *testing.T) {
+	saved := savedDefaults(t)
+	saved.SrcKubeConfig = "/kube-config/a"
+	saved.DstContext = "ctx-b"
+
+	o := loadOptions(t, saved)
+	if o.SrcKubeConfig != "/kube-config/a" || o.DstContext != "ctx-b" {
+		t.Errorf("src-kubeconfig = %s, dst-context = %s, want config.json", o.SrcKubeConfig, o.DstContext)
+	}
+	o = loadOptions(t, saved, "--src-context", "ctx-c")
+	if o.SrcKubeConfig != "" || o.HasDst() || o.SrcContext != "ctx-c" {
+		t.Errorf("src-kubeconfig = %s, src-context = %s, dst-context = %s, want the single cluster of src-context",
+			o.SrcKubeConfig, o.SrcContext, o.DstContext)
+	}
+}
+
+func TestLoadConfigCIDRsMap(t *testing.T) {
+	saved := savedDefaults(t)
+	saved.DstKubeConfig = "/kube-config/b"
+	saved.DstClusterName = "cluster-b"
+	saved.CIDRsMap = map[string]string{"10.222.0.0/16": "210.222.0.0/16"}
+
+	o := loadOptions(t, saved)
+	if o.DstClusterName != "cluster-b" || len(o.CIDRsMap) != 1 {
+		t.Errorf("dst-cluster-name = %s, cidrs-map = %v, want config.json", o.DstClusterName, o.CIDRsMap)
+	}
+	o = loadOptions(t, saved, "--cidrs-map", "10.233.0.0/16=210.233.0.0/16")
+	if o.DstClusterName != "cluster-b" || o.CIDRsMap["10.233.0.0/16"] != "210.233.0.0/16" || len(o.CIDRsMap) != 1 {
+		t.Errorf("dst-cluster-name = %s, cidrs-map = %v, want the cidrs-map flag", o.DstClusterName, o.CIDRsMap)
+	}
+	o = loadOptions(t, saved, "--dst-kubeconfig", "/kube-config/c")
+	if o.DstClusterName != "" || len(o.CIDRsMap) > 0 {
+		t.Errorf("dst-cluster-name = %s, cidrs-map = %v, want none for another destination", o.DstClusterName, o.CIDRsMap)
+	}
+}
+
+func TestLoadConfigFailThreshold(t *testing.T) {
+	saved := savedDefaults(t)
+	saved.FailThreshold = 10
+
+	if o := loadOptions(t, saved); o.FailThreshold != 10 {
+		t.Errorf("fail-threshold = %v, want 10 from config.json", o.FailThreshold)
+	}
+	if o := loadOptions(t, saved, "--fail-threshold", "0"); o.FailThreshold != 0 {
+		t.Errorf("fail-threshold = %v, want 0", o.FailThreshold)
+	}
+}
+
+func TestLoadConfigEnableAnalysis(t *testing.T) {
+	saved := savedDefaults(t)
+	saved.EnableAnalysis = true
+
+	if o := loadOptions(t, saved); !o.EnableAnalysis {
+		t.Error("enable-analysis was not read from config.json")
+	}
+	if o := loadOptions(t, saved, "--enable-analysis=false"); o.EnableAnalysis {
+		t.Error("enable-analysis=false was overridden by config.json")
+	}
+}
+
+func TestLoadConfigNodeSelection(t *testing.T) {
+	saved := savedDefaults(t)
+	saved.Nodes = []string{"node-1", "node-2"}
+	saved.SamplePerZone = 2
+
+	o := loadOptions(t, saved)
+	if len(o.Nodes) != 2 || o.SamplePerZone != 2 {
+		t.Errorf("nodes = %v, sample-per-zone = %d, want config.json", o.Nodes, o.SamplePerZone)
+	}
+	o = loadOptions(t, saved, "--nodes", "node-3", "--sample", "5")
+	if len(o.Nodes) != 1 || o.Sample != 5 || o.SamplePerZone != 0 {
+		t.Errorf("nodes = %v, sample = %d, sample-per-zone = %d, want the flags", o.Nodes, o.Sample, o.SamplePerZone)
+	}
+}
+
+func TestLoadConfigRunID(t *testing.T) {
+	saved := savedDefaults(t)
+	saved.RunID = "1a2b3c4d"
+	saved.Owner = "bob@bastion"
+
+	if o := loadOptions(t, saved); len(o.RunID) > 0 {
+		t.Errorf("run-id = %s, want a new run for a check of another operator", o.RunID)
+	}
+	if o := loadOptions(t, saved, "--run-id", "5e6f7a8b"); o.RunID != "5e6f7a8b" {
+		t.Errorf("run-id = %s, want the flag", o.RunID)
+	}
+
+	useConfig(t, saved)
+	_, o := NewOptions(nil)
+	o.reuseRun = true
+	if o.LoadConfig(); o.RunID != "1a2b3c4d" {
+		t.Errorf("run-id = %s, want the run of config.json for resume and clean", o.RunID)
+	}
+}
+
+// TestRunReusedByTheOperator runs check, check and clean from the same directory, the second check continues
+// the run of the first one so that clean removes every floater they left.
+func TestRunReusedByTheOperator(t *testing.T) {
+	useConfig(t, nil)
+
+	start := func(reuseRun bool) *CommandCheckOptions {
+		_, o := NewOptions(nil)
+		o.reuseRun = reuseRun
+		o.LoadConfig()
+		o.completeRunID()
+		return o
+	}
+
+	first := start(false)
+	if !first.newRun || first.Owner != RunOwner() {
+		t.Fatalf("first check run %s of %q, want a new run of %s", first.RunID, first.Owner, RunOwner())
+	}
+	first.SaveOpts()
+
+	second := start(false)
+	if second.newRun || second.RunID != first.RunID {
+		t.Fatalf("second check run %s, want the run %s of the first check", second.RunID, first.RunID)
+	}
+	second.SaveOpts()
+
+	clean := start(true)
+	if clean.newRun || clean.RunID != first.RunID {
+		t.Fatalf("clean run %s, want the run %s of both checks", clean.RunID, first.RunID)
+	}
+	for _, hostNetwork := range []bool{false, true} {
+		left := NewCheckFloater(first, false).WithHostNetwork(hostNetwork)
+		removed := NewCheckFloater(clean, false).WithHostNetwork(hostNetwork)
+		if left.Name != removed.Name || left.rbacName() != removed.rbacName() {
+			t.Errorf("clean removes %s and %s, the checks left %s and %s", removed.Name, removed.rbacName(), left.Name, left.rbacName())
+		}
+	}
+}
+
+// TestSkip checks that resume re-runs a failed record only in the mode, direction and clusters it failed in.
+func TestSkip(t *testing.T) {
+	o := &CommandCheckOptions{ResumeRecord: []*PrintCheckData{
+		{SrcNodeName: "node-1", TargetIP: "10.233.64.12", Mode: string(ModePodPod), Direction: DirectionReverse, SrcCluster: "cluster-85", DstCluster: "cluster-84"},
+	}}
+	node := &FloatInfo{NodeName: "node-1"}
+
+	o.scope = checkScope{Mode: string(ModePodPod), Direction: DirectionReverse, SrcCluster: "cluster-85", DstCluster: "cluster-84"}
+	if o.Skip(node, "10.233.64.12") {
+		t.Error("the failed record was skipped")
+	}
+
+	for _, scope := range []checkScope{
+		{Mode: string(ModeNodePod), Direction: DirectionReverse, SrcCluster: "cluster-85", DstCluster: "cluster-84"},
+		{Mode: string(ModePodPod), Direction: DirectionForward, SrcCluster: "cluster-85", DstCluster: "cluster-84"},
+		{Mode: string(ModePodPod), Direction: DirectionReverse, SrcCluster: "cluster-84", DstCluster: "cluster-85"},
+	} {
+		o.scope = scope
+		if !o.Skip(node, "10.233.64.12") {
+			t.Errorf("the record of %+v was re-run in %+v", o.ResumeRecord[0].scope(), scope)
+		}
+	}
+
+	if o := (&CommandCheckOptions{}); o.Skip(node, "10.233.64.12") {
+		t.Error("check skipped a target without resume records")
+	}
+}
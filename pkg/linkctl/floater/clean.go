@@ -1,23 +1,30 @@
 package floater
 
 import (
+	"fmt"
+
 	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
 	ctlutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/util/i18n"
 )
 
 type CommandCleanOptions struct {
-	CommandCheckOptions
+	*CommandCheckOptions
+
+	// Legacy removes the cluster-wide RBAC objects of older versions of linkctl
+	Legacy bool
 }
 
-func NewCmdClean() *cobra.Command {
-	cmd, checkOpt := NewOptions()
+func NewCmdClean(configFlags *genericclioptions.ConfigFlags) *cobra.Command {
+	cmd, checkOpt := NewOptions(configFlags)
+	checkOpt.reuseRun = true
 
-	o := &CommandResumeOptions{}
-	o.CommandCheckOptions = *checkOpt
+	o := &CommandCleanOptions{CommandCheckOptions: checkOpt}
 	cmd.Use = "clean"
 	cmd.Short = i18n.T("clean network connectivity between Kosmos clusters")
 	cmd.Example = checkExample
+	cmd.Flags().BoolVar(&o.Legacy, "legacy", false, "Also remove the cluster-wide clusterlink-floater ClusterRole and ClusterRoleBinding of older versions of linkctl, no run is required.")
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
 		ctlutil.CheckErr(o.Complete())
 		ctlutil.CheckErr(o.Validate())
@@ -27,6 +34,55 @@ func NewCmdClean() *cobra.Command {
 	return cmd
 }
 
+func (o *CommandCleanOptions) Validate() error {
+	if err := o.validateRun(); err != nil {
+		return err
+	}
+	return o.CommandCheckOptions.Validate()
+}
+
+// validateRun requires a run to clean unless only the RBAC of older versions is removed.
+func (o *CommandCleanOptions) validateRun() error {
+	if o.newRun && !o.Legacy {
+		return fmt.Errorf("no run to clean, pass --run-id or run linkctl check first")
+	}
+	if o.Legacy && o.Namespaced {
+		return fmt.Errorf("--legacy removes cluster-wide objects, it can not be used with --namespaced")
+	}
+	return nil
+}
+
 func (o *CommandCleanOptions) Run() error {
-	return o.Clean()
+	if !o.newRun {
+		if err := o.Clean(); err != nil {
+			return err
+		}
+	}
+	if o.Legacy {
+		return o.cleanLegacy()
+	}
+	return nil
+}
+
+// cleanLegacy removes the cluster-wide RBAC objects of older versions in every cluster, an operator who is
+// not allowed to delete them is reported.
+func (o *CommandCleanOptions) cleanLegacy() error {
+	var floaters []*Floater
+	if o.MultiCluster() {
+		for _, member := range o.Members {
+			floaters = append(floaters, member.Floater)
+		}
+	} else {
+		floaters = append(floaters, o.SrcFloater)
+		if o.HasDst() {
+			floaters = append(floaters, o.DstFloater)
+		}
+	}
+
+	for _, f := range floaters {
+		if err := f.RemoveLegacyClusterRBAC(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,38 @@
+package floater
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestCleanValidateRun checks that clean requires a run unless it only removes the RBAC of older versions,
+// which are cluster-wide and out of reach of a namespaced clean.
+func TestCleanValidateRun(t *testing.T) {
+	useConfig(t, nil)
+
+	clean := func(args ...string) *CommandCleanOptions {
+		cmd, o := NewOptions(nil)
+		o.reuseRun = true
+		c := &CommandCleanOptions{CommandCheckOptions: o}
+		cmd.Flags().BoolVar(&c.Legacy, "legacy", false, "")
+		if err := cmd.Flags().Parse(args); err != nil {
+			t.Fatalf("parse %v failed: %v", args, err)
+		}
+		o.LoadConfig()
+		o.completeRunID()
+		return c
+	}
+
+	if err := clean().validateRun(); err == nil || !strings.Contains(err.Error(), "no run to clean") {
+		t.Errorf("validateRun() error = %v, want no run to clean", err)
+	}
+	if err := clean("--legacy").validateRun(); err != nil {
+		t.Errorf("validateRun() error = %v, want --legacy without run", err)
+	}
+	if err := clean("--legacy", "--namespaced").validateRun(); err == nil || !strings.Contains(err.Error(), "--namespaced") {
+		t.Errorf("validateRun() error = %v, want --legacy rejected with --namespaced", err)
+	}
+	if err := clean("--run-id", "1c474ad5").validateRun(); err != nil {
+		t.Errorf("validateRun() error = %v, want the run of --run-id", err)
+	}
+}
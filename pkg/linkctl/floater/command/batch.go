@@ -0,0 +1,61 @@
+package command
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	batchBegin = "===LINKCTL-BEGIN"
+	batchEnd   = "===LINKCTL-END"
+)
+
+// GetBatchCommandStr runs all commands concurrently in one shell, the output of every command is
+// framed by markers carrying its index once all of them are done.
+func GetBatchCommandStr(cmds []Command) string {
+	var b strings.Builder
+	b.WriteString("dir=$(mktemp -d)\n")
+	for i, cmd := range cmds {
+		fmt.Fprintf(&b, "(\n%s\n) > $dir/%d 2>&1 &\n", cmd.GetCommandStr(), i)
+	}
+	b.WriteString("wait\n")
+	fmt.Fprintf(&b, "for i in $(seq 0 %d); do echo \"%s $i\"; cat $dir/$i; echo; echo \"%s $i\"; done\n", len(cmds)-1, batchBegin, batchEnd)
+	b.WriteString("rm -rf $dir\n")
+	return b.String()
+}
+
+// ParseBatchResult splits the output of GetBatchCommandStr and parses every part with its command,
+// commands missing from the output are reported as exceptions.
+func ParseBatchResult(cmds []Command, result string) []*Result {
+	outputs := map[int]*strings.Builder{}
+	current := -1
+	for _, line := range strings.Split(result, "\n") {
+		if strings.HasPrefix(line, batchBegin+" ") {
+			if i, err := strconv.Atoi(strings.TrimPrefix(line, batchBegin+" ")); err == nil {
+				current = i
+				outputs[i] = &strings.Builder{}
+			}
+			continue
+		}
+		if strings.HasPrefix(line, batchEnd+" ") {
+			current = -1
+			continue
+		}
+		if output, ok := outputs[current]; ok {
+			output.WriteString(line)
+			output.WriteString("\n")
+		}
+	}
+
+	results := make([]*Result, 0, len(cmds))
+	for i, cmd := range cmds {
+		output, ok := outputs[i]
+		if !ok {
+			results = append(results, ParseError(fmt.Errorf("no result in the batch output")))
+			continue
+		}
+		results = append(results, cmd.ParseResult(strings.TrimSuffix(output.String(), "\n\n")))
+	}
+	return results
+}
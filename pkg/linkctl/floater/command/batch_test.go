@@ -0,0 +1,76 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// frame wraps the output of the command i like the shell of GetBatchCommandStr does.
+func frame(i int, output string) string {
+	return fmt.Sprintf("%s %d\n%s\n\n%s %d\n", batchBegin, i, output, batchEnd, i)
+}
+
+func busyboxPing(ip string, received int) string {
+	out := fmt.Sprintf("PING %[1]s (%[1]s): 56 data bytes\n", ip)
+	if received > 0 {
+		out += fmt.Sprintf("64 bytes from %s: seq=0 ttl=62 time=0.512 ms\n", ip)
+	}
+	out += fmt.Sprintf("\n--- %s ping statistics ---\n1 packets transmitted, %d packets received, %d%% packet loss\n", ip, received, 100-100*received)
+	if received > 0 {
+		out += "round-trip min/avg/max = 0.512/0.512/0.512 ms\n"
+	}
+	return out
+}
+
+func assertStatuses(t *testing.T, results []*Result, want ...int) {
+	t.Helper()
+	if len(results) != len(want) {
+		t.Fatalf("ParseBatchResult() returned %d results, want %d", len(results), len(want))
+	}
+	for i, r := range results {
+		if r.Status != want[i] {
+			t.Errorf("result %d status = %s, want %s: %s", i, PrintStatus(r.Status), PrintStatus(want[i]), r.ResultStr)
+		}
+	}
+}
+
+func TestParseBatchResult(t *testing.T) {
+	ips := []string{"10.233.64.12", "10.233.64.99", "10.233.65.7"}
+	var cmds []Command
+	for _, ip := range ips {
+		cmds = append(cmds, &Ping{TargetIP: ip})
+	}
+
+	output := frame(0, busyboxPing(ips[0], 1)) + frame(1, busyboxPing(ips[1], 0)) + frame(2, busyboxPing(ips[2], 1))
+	assertStatuses(t, ParseBatchResult(cmds, output), CommandSuccessed, CommandFailed, CommandSuccessed)
+
+	// the exec session ended while the second command was printed, the third one never was
+	interrupted := frame(0, busyboxPing(ips[0], 1)) + fmt.Sprintf("%s 1\nPING %s (%s): 56 data bytes\n", batchBegin, ips[1], ips[1])
+	assertStatuses(t, ParseBatchResult(cmds, interrupted), CommandSuccessed, CommandFailed, ExecError)
+
+	assertStatuses(t, ParseBatchResult(cmds, ""), ExecError, ExecError, ExecError)
+}
+
+func TestParseBatchResultOutput(t *testing.T) {
+	cmds := []Command{&Ping{TargetIP: "10.233.64.12"}}
+
+	results := ParseBatchResult(cmds, frame(0, "ping: sendto: Network unreachable"))
+	if got, want := results[0].ResultStr, "ping: sendto: Network unreachable"; got != want {
+		t.Errorf("ParseBatchResult() output = %q, want %q", got, want)
+	}
+}
+
+func TestGetBatchCommandStr(t *testing.T) {
+	cmds := []Command{&Ping{TargetIP: "10.233.64.12"}, &Ping{TargetIP: "10.233.64.99"}}
+
+	script := GetBatchCommandStr(cmds)
+	for i, cmd := range cmds {
+		if !strings.Contains(script, fmt.Sprintf("(\n%s\n) > $dir/%d 2>&1 &\n", cmd.GetCommandStr(), i)) {
+			t.Errorf("command %d is not run in the background of the batch:\n%s", i, script)
+		}
+	}
+	if !strings.Contains(script, "$(seq 0 1)") {
+		t.Errorf("the batch does not print the output of both commands:\n%s", script)
+	}
+}
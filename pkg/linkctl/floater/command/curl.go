@@ -2,26 +2,30 @@ package command
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/kosmos.io/linkctl/pkg/utils"
 )
 
 type Curl struct {
 	TargetIP string
+	Port     string
+	Timeout  int
 }
 
 func (c *Curl) GetCommandStr() string {
-	// execute once
+	url := fmt.Sprintf("http://%s:%s/", c.TargetIP, c.Port)
 	if utils.IsIPv6(c.TargetIP) {
-		return fmt.Sprintf("curl -k https://[%s]:8889/", c.TargetIP)
+		url = fmt.Sprintf("http://[%s]:%s/", c.TargetIP, c.Port)
 	}
-	return fmt.Sprintf("curl -k https://%s:8889/", c.TargetIP)
+	// the floater image only ships busybox, fall back to wget when curl is missing
+	return fmt.Sprintf("if command -v curl >/dev/null 2>&1; then curl -s -m %[1]d %[2]s; else wget -q -T %[1]d -O - %[2]s; fi", c.Timeout, url)
 }
 
 func (c *Curl) ParseResult(result string) *Result {
 	// klog.Infof("curl result parser: %s", result)
 	isSucceed := CommandSuccessed
-	if result != "OK" {
+	if strings.TrimSpace(result) != "OK" {
 		isSucceed = CommandFailed
 	}
 	return &Result{
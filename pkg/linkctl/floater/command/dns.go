@@ -0,0 +1,70 @@
+package command
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kosmos.io/linkctl/pkg/results"
+)
+
+// DNS resolves Name with busybox nslookup against Server, Expect is an optional address the answer must contain.
+type DNS struct {
+	Server  string
+	Name    string
+	Expect  string
+	Timeout int
+}
+
+var (
+	nslookupAddressRegexp = regexp.MustCompile(`^Address(?: \d+)?:\s+(\S+)`)
+	dnsElapsedRegexp      = regexp.MustCompile(`elapsed ns: (\d+)`)
+	dnsExitCodeRegexp     = regexp.MustCompile(`exit code: (\d+)`)
+)
+
+func (c *DNS) GetCommandStr() string {
+	// date +%N is only supported by some busybox builds, the latency is left out when it prints %N
+	return fmt.Sprintf(`s=$(date +%%s%%N); out=$(timeout %d nslookup %s %s 2>&1); rc=$?; e=$(date +%%s%%N)
+echo "$out"
+echo "exit code: $rc"
+case "$s$e" in *N*) ;; *) echo "elapsed ns: $((e-s))";; esac`, c.Timeout, c.Name, c.Server)
+}
+
+func (c *DNS) ParseResult(result string) *Result {
+	r := &Result{
+		Status:    CommandFailed,
+		ResultStr: result,
+	}
+	addresses := ParseNslookupAddresses(result)
+	if match := dnsExitCodeRegexp.FindStringSubmatch(result); match != nil && match[1] == "0" && len(addresses) > 0 {
+		r = results.NewDNSResult(c.Name, c.Server, c.Expect, addresses)
+	}
+
+	if match := dnsElapsedRegexp.FindStringSubmatch(result); match != nil {
+		ns, _ := strconv.ParseInt(match[1], 10, 64)
+		r.Elapsed = time.Duration(ns)
+	}
+	return r
+}
+
+// ParseNslookupAddresses returns the addresses of the answer, the address of the server printed before it is skipped.
+func ParseNslookupAddresses(result string) []string {
+	var addresses []string
+	answer := false
+	for _, line := range strings.Split(result, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "Name:") {
+			answer = true
+			continue
+		}
+		if !answer {
+			continue
+		}
+		if match := nslookupAddressRegexp.FindStringSubmatch(line); match != nil {
+			addresses = append(addresses, match[1])
+		}
+	}
+	return addresses
+}
@@ -0,0 +1,82 @@
+package command
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+const (
+	busyboxAnswer = `Server:		10.96.0.10
+Address:	10.96.0.10:53
+
+Name:	kubernetes.default.svc.cluster.local
+Address: 10.96.0.1
+
+`
+	busyboxDualStackAnswer = `Server:		10.96.0.10
+Address:	10.96.0.10:53
+
+Name:	floater.kosmos-system.svc.cluster.local
+Address: 10.233.12.40
+
+Name:	floater.kosmos-system.svc.cluster.local
+Address: fd11:1111:1111:15::2a
+
+`
+	// busybox 1.27 prints the server and the answer with numbered addresses
+	busyboxOldAnswer = `Server:    10.96.0.10
+Address 1: 10.96.0.10 kube-dns.kube-system.svc.cluster.local
+
+Name:      kubernetes.default
+Address 1: 10.96.0.1 kubernetes.default.svc.cluster.local
+`
+	nxdomainAnswer = `Server:		10.96.0.10
+Address:	10.96.0.10:53
+
+** server can't find nginx.default.svc.cluster.local: NXDOMAIN
+
+`
+)
+
+func TestParseNslookupAddresses(t *testing.T) {
+	cases := []struct {
+		output string
+		want   []string
+	}{
+		{busyboxAnswer, []string{"10.96.0.1"}},
+		{busyboxDualStackAnswer, []string{"10.233.12.40", "fd11:1111:1111:15::2a"}},
+		{busyboxOldAnswer, []string{"10.96.0.1"}},
+		{nxdomainAnswer, nil},
+		{";; connection timed out; no servers could be reached\n\n", nil},
+	}
+
+	for _, c := range cases {
+		if got := ParseNslookupAddresses(c.output); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("ParseNslookupAddresses() = %v, want %v for:\n%s", got, c.want, c.output)
+		}
+	}
+}
+
+func TestDNSParseResult(t *testing.T) {
+	c := &DNS{Server: "10.96.0.10", Name: "kubernetes.default.svc.cluster.local.", Timeout: 3}
+
+	r := c.ParseResult(busyboxAnswer + "exit code: 0\nelapsed ns: 1500000\n")
+	if r.Status != CommandSuccessed || r.Elapsed != 1500*time.Microsecond {
+		t.Errorf("ParseResult() = %s in %v, want SUCCESSED in 1.5ms", PrintStatus(r.Status), r.Elapsed)
+	}
+
+	// date +%N is not supported by every busybox, the latency is left out
+	if r = c.ParseResult(busyboxAnswer + "exit code: 0\n"); r.Status != CommandSuccessed || r.Elapsed != 0 {
+		t.Errorf("ParseResult() = %s in %v, want SUCCESSED without latency", PrintStatus(r.Status), r.Elapsed)
+	}
+
+	if r = c.ParseResult(nxdomainAnswer + "exit code: 1\n"); r.Status != CommandFailed {
+		t.Errorf("ParseResult() = %s, want FAILED on NXDOMAIN", PrintStatus(r.Status))
+	}
+
+	c.Expect = "10.96.0.2"
+	if r = c.ParseResult(busyboxAnswer + "exit code: 0\n"); r.Status != CommandFailed {
+		t.Errorf("ParseResult() = %s, want FAILED when the answer misses %s", PrintStatus(r.Status), c.Expect)
+	}
+}
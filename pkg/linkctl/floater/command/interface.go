@@ -1,17 +1,21 @@
 package command
 
-import "fmt"
+import (
+	"github.com/kosmos.io/linkctl/pkg/results"
+)
 
 const (
-	ExecError = iota
-	CommandSuccessed
-	CommandFailed
+	ExecError        = results.ExecError
+	CommandSuccessed = results.CommandSuccessed
+	CommandFailed    = results.CommandFailed
 )
 
-type Result struct {
-	Status    int
-	ResultStr string
-}
+// Result, PingStatistics and MTUStatistics are shared with the probe API of the floater
+type (
+	Result         = results.Result
+	PingStatistics = results.PingStatistics
+	MTUStatistics  = results.MTUStatistics
+)
 
 type Command interface {
 	GetCommandStr() string
@@ -19,21 +23,9 @@ type Command interface {
 }
 
 func ParseError(err error) *Result {
-	return &Result{
-		Status:    ExecError,
-		ResultStr: fmt.Sprintf("exec error: %s", err),
-	}
+	return results.ParseError(err)
 }
 
 func PrintStatus(status int) string {
-	if status == ExecError {
-		return "EXCEPTION"
-	}
-	if status == CommandSuccessed {
-		return "SUCCESSED"
-	}
-	if status == CommandFailed {
-		return "FAILED"
-	}
-	return "UNEXCEPTIONED"
+	return results.PrintStatus(status)
 }
@@ -0,0 +1,32 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// MTUProbeTimeout the binary search sends up to 12 pings which wait 1 second each when they are dropped.
+const MTUProbeTimeout = 15
+
+// MTU binary-searches the largest don't-fragment echo request that reaches TargetIP, busybox ping can not set
+// the DF bit so the floater runs the search natively and prints its result as json.
+type MTU struct {
+	TargetIP string
+	Timeout  int
+}
+
+func (c *MTU) GetCommandStr() string {
+	return fmt.Sprintf("clusterlink-floater probe --protocol mtu --target %s --timeout %d", c.TargetIP, c.Timeout)
+}
+
+func (c *MTU) ParseResult(result string) *Result {
+	r := &Result{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(result)), r); err != nil {
+		return &Result{
+			Status:    CommandFailed,
+			ResultStr: result,
+		}
+	}
+	return r
+}
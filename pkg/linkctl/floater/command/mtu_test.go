@@ -0,0 +1,18 @@
+package command
+
+import "testing"
+
+func TestMTUParseResult(t *testing.T) {
+	c := &MTU{TargetIP: "10.233.64.12", Timeout: 14}
+
+	r := c.ParseResult(`{"Status":1,"ResultStr":"interface mtu: 1450\nmax payload: 1372","Elapsed":5000000,"MTU":{"pathMTU":1400,"interfaceMTU":1450}}` + "\n")
+	if r.Status != CommandSuccessed || r.MTU == nil || r.MTU.PathMTU != 1400 || r.MTU.InterfaceMTU != 1450 {
+		t.Errorf("ParseResult() = %+v, want a path mtu of 1400 on an interface mtu of 1450", r)
+	}
+
+	// floater images older than the probe command
+	out := `Error: "clusterlink-floater" does not take any arguments, got ["probe"]`
+	if r = c.ParseResult(out); r.Status != CommandFailed || r.ResultStr != out {
+		t.Errorf("ParseResult() = %+v, want the failed output", r)
+	}
+}
@@ -2,28 +2,100 @@ package command
 
 import (
 	"fmt"
+	"math"
 	"regexp"
+	"strconv"
 )
 
-var pingReg, _ = regexp.Compile(`PING[\s\S]*1\spackets\stransmitted,\s1\spackets\sreceived,\s0[%]\spacket\sloss[\s\S]*$`)
+var (
+	// busybox: 3 packets transmitted, 3 packets received, 0% packet loss
+	// iputils: 3 packets transmitted, 3 received, +1 errors, 0% packet loss, time 2003ms
+	pingLossReg = regexp.MustCompile(`(\d+) packets transmitted, (\d+) (?:packets )?received,.*?([\d.]+)% packet loss`)
+	// busybox: round-trip min/avg/max = 0.058/0.070/0.082 ms
+	// iputils: rtt min/avg/max/mdev = 0.058/0.070/0.082/0.010 ms
+	pingRTTReg  = regexp.MustCompile(`(?:rtt|round-trip) min/avg/max(?:/mdev)? = ([\d.]+)/([\d.]+)/([\d.]+)(?:/([\d.]+))? ms`)
+	pingTimeReg = regexp.MustCompile(`time=([\d.]+) ms`)
+)
 
 type Ping struct {
 	TargetIP string
+	// Count of echo requests, 1 if not set
+	Count int
+	// Interval between echo requests in seconds
+	Interval float64
+	// PacketSize of the echo payload in bytes
+	PacketSize int
+	// Timeout is the deadline of the whole ping in seconds
+	Timeout int
 }
 
 func (c *Ping) GetCommandStr() string {
-	// execute once
-	return fmt.Sprintf("ping -c 1 %s", c.TargetIP)
+	count := c.Count
+	if count < 1 {
+		count = 1
+	}
+	cmd := fmt.Sprintf("ping -c %d", count)
+	if count > 1 && c.Interval > 0 {
+		cmd = fmt.Sprintf("%s -i %s", cmd, strconv.FormatFloat(c.Interval, 'f', -1, 64))
+	}
+	if c.PacketSize > 0 {
+		cmd = fmt.Sprintf("%s -s %d", cmd, c.PacketSize)
+	}
+	if c.Timeout > 0 {
+		cmd = fmt.Sprintf("%s -w %d", cmd, c.Timeout)
+	}
+	return fmt.Sprintf("%s %s", cmd, c.TargetIP)
 }
 
 func (c *Ping) ParseResult(result string) *Result {
 	// klog.Infof("ping result parser: %s", result)
+	stats := ParsePingStatistics(result)
 	isSucceed := CommandSuccessed
-	if !pingReg.MatchString(result) {
+	if stats == nil || stats.Received == 0 {
 		isSucceed = CommandFailed
 	}
 	return &Result{
-		Status:    isSucceed,
-		ResultStr: result,
+		Status:     isSucceed,
+		ResultStr:  result,
+		Statistics: stats,
+	}
+}
+
+// ParsePingStatistics reads the summary of busybox or iputils ping, busybox does not print mdev so it
+// is computed from the reply times.
+func ParsePingStatistics(result string) *PingStatistics {
+	match := pingLossReg.FindStringSubmatch(result)
+	if match == nil {
+		return nil
 	}
+	stats := &PingStatistics{}
+	stats.Transmitted, _ = strconv.Atoi(match[1])
+	stats.Received, _ = strconv.Atoi(match[2])
+	stats.Loss, _ = strconv.ParseFloat(match[3], 64)
+
+	match = pingRTTReg.FindStringSubmatch(result)
+	if match == nil {
+		return stats
+	}
+	stats.Min, _ = strconv.ParseFloat(match[1], 64)
+	stats.Avg, _ = strconv.ParseFloat(match[2], 64)
+	stats.Max, _ = strconv.ParseFloat(match[3], 64)
+	if len(match[4]) > 0 {
+		stats.Mdev, _ = strconv.ParseFloat(match[4], 64)
+		return stats
+	}
+
+	var sum, sumSquare float64
+	times := pingTimeReg.FindAllStringSubmatch(result, -1)
+	for _, t := range times {
+		v, _ := strconv.ParseFloat(t[1], 64)
+		sum += v
+		sumSquare += v * v
+	}
+	if len(times) > 0 {
+		mean := sum / float64(len(times))
+		stats.Mdev = math.Sqrt(math.Max(sumSquare/float64(len(times))-mean*mean, 0))
+	}
+
+	return stats
 }
@@ -0,0 +1,95 @@
+package command
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParsePingStatistics(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   *PingStatistics
+	}{
+		{
+			name: "busybox",
+			output: `PING 10.233.64.12 (10.233.64.12): 56 data bytes
+64 bytes from 10.233.64.12: seq=0 ttl=62 time=0.512 ms
+64 bytes from 10.233.64.12: seq=1 ttl=62 time=0.431 ms
+64 bytes from 10.233.64.12: seq=2 ttl=62 time=0.466 ms
+
+--- 10.233.64.12 ping statistics ---
+3 packets transmitted, 3 packets received, 0% packet loss
+round-trip min/avg/max = 0.431/0.469/0.512 ms
+`,
+			want: &PingStatistics{Transmitted: 3, Received: 3, Loss: 0, Min: 0.431, Avg: 0.469, Max: 0.512, Mdev: 0.0332},
+		},
+		{
+			name: "busybox partial loss",
+			output: `PING 10.233.64.12 (10.233.64.12): 56 data bytes
+64 bytes from 10.233.64.12: seq=0 ttl=62 time=1.204 ms
+64 bytes from 10.233.64.12: seq=2 ttl=62 time=1.204 ms
+
+--- 10.233.64.12 ping statistics ---
+4 packets transmitted, 2 packets received, 50% packet loss
+round-trip min/avg/max = 1.204/1.204/1.204 ms
+`,
+			want: &PingStatistics{Transmitted: 4, Received: 2, Loss: 50, Min: 1.204, Avg: 1.204, Max: 1.204, Mdev: 0},
+		},
+		{
+			name: "busybox unreachable",
+			output: `PING 10.233.64.99 (10.233.64.99): 56 data bytes
+
+--- 10.233.64.99 ping statistics ---
+3 packets transmitted, 0 packets received, 100% packet loss
+`,
+			want: &PingStatistics{Transmitted: 3, Received: 0, Loss: 100},
+		},
+		{
+			name: "iputils",
+			output: `PING 10.233.64.12 (10.233.64.12) 56(84) bytes of data.
+64 bytes from 10.233.64.12: icmp_seq=1 ttl=62 time=0.512 ms
+64 bytes from 10.233.64.12: icmp_seq=2 ttl=62 time=0.431 ms
+64 bytes from 10.233.64.12: icmp_seq=3 ttl=62 time=0.466 ms
+
+--- 10.233.64.12 ping statistics ---
+3 packets transmitted, 3 received, 0% packet loss, time 2003ms
+rtt min/avg/max/mdev = 0.431/0.469/0.512/0.033 ms
+`,
+			want: &PingStatistics{Transmitted: 3, Received: 3, Loss: 0, Min: 0.431, Avg: 0.469, Max: 0.512, Mdev: 0.033},
+		},
+		{
+			name: "iputils errors",
+			output: `PING 10.233.64.99 (10.233.64.99) 56(84) bytes of data.
+From 10.233.64.1 icmp_seq=1 Destination Host Unreachable
+From 10.233.64.1 icmp_seq=2 Destination Host Unreachable
+From 10.233.64.1 icmp_seq=3 Destination Host Unreachable
+
+--- 10.233.64.99 ping statistics ---
+3 packets transmitted, 0 received, +3 errors, 100% packet loss, time 2040ms
+`,
+			want: &PingStatistics{Transmitted: 3, Received: 0, Loss: 100},
+		},
+		{
+			name:   "bad address",
+			output: "ping: bad address 'clusterlink-floater'\n",
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParsePingStatistics(tt.output)
+			if tt.want == nil || got == nil {
+				if tt.want != got {
+					t.Fatalf("ParsePingStatistics() = %+v, want %+v", got, tt.want)
+				}
+				return
+			}
+			if got.Transmitted != tt.want.Transmitted || got.Received != tt.want.Received || got.Loss != tt.want.Loss ||
+				got.Min != tt.want.Min || got.Avg != tt.want.Avg || got.Max != tt.want.Max || math.Abs(got.Mdev-tt.want.Mdev) > 0.0005 {
+				t.Errorf("ParsePingStatistics() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+)
+
+const tcpConnected = "tcp connected"
+
+type TCP struct {
+	TargetIP string
+	Port     string
+	Timeout  int
+}
+
+func (c *TCP) GetCommandStr() string {
+	// zero-I/O mode, only check the handshake
+	return fmt.Sprintf("nc -z -w %d %s %s && echo '%s'", c.Timeout, c.TargetIP, c.Port, tcpConnected)
+}
+
+func (c *TCP) ParseResult(result string) *Result {
+	isSucceed := CommandSuccessed
+	if !strings.Contains(result, tcpConnected) {
+		isSucceed = CommandFailed
+	}
+	return &Result{
+		Status:    isSucceed,
+		ResultStr: result,
+	}
+}
@@ -0,0 +1,36 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kosmos.io/linkctl/pkg/results"
+)
+
+// UDPEchoPayload is the datagram the floaters echo back
+const UDPEchoPayload = results.UDPEchoPayload
+
+type UDP struct {
+	TargetIP string
+	Port     string
+	Timeout  int
+}
+
+func (c *UDP) GetCommandStr() string {
+	// the floater echoes the datagram back, nc waits Timeout seconds for it
+	return fmt.Sprintf("echo -n '%s' | nc -u -w %d %s %s", UDPEchoPayload, c.Timeout, c.TargetIP, c.Port)
+}
+
+func (c *UDP) ParseResult(result string) *Result {
+	isSucceed := CommandSuccessed
+	if !strings.Contains(result, UDPEchoPayload) {
+		isSucceed = CommandFailed
+		if len(result) == 0 {
+			result = fmt.Sprintf("no udp echo received within %ds", c.Timeout)
+		}
+	}
+	return &Result{
+		Status:    isSucceed,
+		ResultStr: result,
+	}
+}
@@ -0,0 +1,254 @@
+package floater
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/klog/v2"
+	ctlutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/kosmos.io/linkctl/pkg/linkctl/util"
+	"github.com/kosmos.io/linkctl/pkg/utils"
+)
+
+var dnsExample = templates.Examples(i18n.T(`
+        # Resolve cluster.local, kosmos.local, imported service and customer-hosts names from every floater, e.g:
+        linkctl check dns --src-kubeconfig ~/kubeconfig/src-kubeconfig
+
+        # Also resolve some names of your own, e.g:
+        linkctl check dns --src-kubeconfig ~/kubeconfig/src-kubeconfig --names my-svc.my-ns.svc.cluster.local
+`))
+
+const (
+	KosmosClusterDomain = "kosmos.local"
+
+	DefaultKubeDNSService   = "kube-system/kube-dns"
+	DefaultKosmosDNSService = utils.DefaultNamespace + "/coredns"
+	// CustomerHostsConfigMap holds the hosts file served by the Kosmos CoreDNS, see manifest.CorednsCustomerHosts
+	CustomerHostsConfigMap = "coredns-customer-hosts"
+	CustomerHostsKey       = "customer-hosts"
+)
+
+type CommandCheckDNSOptions struct {
+	*CommandCheckOptions
+
+	KubeDNSService   string
+	KosmosDNSService string
+	Names            []string
+}
+
+// dnsName is a name to resolve, the answer must contain Expect when it is set.
+type dnsName struct {
+	Name   string
+	Expect string
+}
+
+func NewCmdCheckDNS(configFlags *genericclioptions.ConfigFlags) *cobra.Command {
+	cmd, checkOpt := NewOptions(configFlags)
+
+	o := &CommandCheckDNSOptions{CommandCheckOptions: checkOpt}
+	cmd.Use = "dns"
+	cmd.Short = i18n.T("Check DNS resolution through kube-dns and the Kosmos CoreDNS from every floater")
+	cmd.Example = dnsExample
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		ctlutil.CheckErr(o.Complete())
+		ctlutil.CheckErr(o.Validate())
+		ctlutil.CheckErr(o.Run())
+		return nil
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&o.KubeDNSService, "kube-dns-service", DefaultKubeDNSService, "Namespace/name of the kube-dns Service.")
+	flags.StringVar(&o.KosmosDNSService, "kosmos-dns-service", DefaultKosmosDNSService, "Namespace/name of the Kosmos CoreDNS Service.")
+	flags.StringSliceVar(&o.Names, "names", nil, "Extra names to resolve.")
+	return cmd
+}
+
+func (o *CommandCheckDNSOptions) Validate() error {
+	if o.MultiCluster() {
+		return fmt.Errorf("check dns only covers the cluster of src-kubeconfig, kubeconfig-list, contexts and all-clusters are not supported")
+	}
+	if len(o.Mode) > 0 {
+		return fmt.Errorf("mode is not supported by check dns, the names are resolved from the floaters of --host-network")
+	}
+	for _, service := range []string{o.KubeDNSService, o.KosmosDNSService} {
+		if _, _, err := splitNamespacedName(service); err != nil {
+			return err
+		}
+	}
+	return o.CommandCheckOptions.Validate()
+}
+
+func (o *CommandCheckDNSOptions) Run() error {
+	if err := o.Preflight(false, o.dnsPermissions()...); err != nil {
+		return err
+	}
+	if err := o.SrcFloater.CreateFloater(); err != nil {
+		return err
+	}
+	// save options for clean, the floaters are left when the check fails
+	o.SaveOpts()
+
+	var srcInfos []*FloatInfo
+	var err error
+	if o.HostNetwork {
+		srcInfos, err = o.SrcFloater.GetNodesInfo()
+	} else {
+		srcInfos, err = o.SrcFloater.GetPodInfo()
+	}
+	if err != nil {
+		return fmt.Errorf("get src cluster floaterInfos failed: %s", err)
+	}
+
+	names := o.dnsNames()
+	var targets []checkTarget
+	for _, service := range []string{o.KubeDNSService, o.KosmosDNSService} {
+		serverIP, err := o.SrcFloater.GetServiceIP(service)
+		if err != nil {
+			klog.Warningf("skip dns server %s: %v", service, err)
+			continue
+		}
+		serverName := fmt.Sprintf("%s(%s)", service, serverIP)
+		for _, name := range names {
+			targets = append(targets, checkTarget{
+				DstNodeName: serverName,
+				TargetIP:    name.Name,
+				Protocol:    DNS,
+				DNSServer:   serverIP,
+				Expect:      name.Expect,
+			})
+		}
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("none of the dns servers %s and %s was found", o.KubeDNSService, o.KosmosDNSService)
+	}
+
+	resultData := o.runTargets(o.SrcFloater.SelectInfos(srcInfos), targets)
+	o.printResultData(resultData)
+
+	if o.AutoClean {
+		if err = o.Clean(); err != nil {
+			return err
+		}
+	}
+
+	return NewCheckSummary(resultData, o.Warning).ExitError(o.FailThreshold)
+}
+
+// dnsPermissions are the reads of the dns servers, the customer hosts and the imported services, the
+// Services and the ConfigMap live in the namespaces of the dns servers. A namespaced check is not required
+// to read them, Run skips the servers and the customer hosts it can not read.
+func (o *CommandCheckDNSOptions) dnsPermissions() []permission {
+	var perms []permission
+	if !o.Namespaced {
+		for _, service := range []string{o.KubeDNSService, o.KosmosDNSService} {
+			namespace, _, _ := splitNamespacedName(service)
+			perms = append(perms, permission{Verb: "get", Resource: "services", Namespaced: true, Namespace: namespace})
+		}
+		namespace, _, _ := splitNamespacedName(o.KosmosDNSService)
+		perms = append(perms, permission{Verb: "get", Resource: "configmaps", Namespaced: true, Namespace: namespace})
+	}
+	// namespaced checks only resolve the services imported into their namespace
+	perms = append(perms, permissions(util.ServiceImportGVR.Group, util.ServiceImportGVR.Resource, o.Namespaced, "list")...)
+	return perms
+}
+
+// dnsNames are the kubernetes Service in both zones served by the Kosmos CoreDNS, the imported services,
+// the customer-hosts entries and the extra names, all fully qualified. A namespaced check only lists the
+// services imported into its namespace.
+func (o *CommandCheckDNSOptions) dnsNames() []dnsName {
+	names := []dnsName{
+		{Name: "kubernetes.default.svc." + o.ClusterDomain},
+		{Name: "kubernetes.default.svc." + KosmosClusterDomain},
+	}
+
+	importNamespace := metav1.NamespaceAll
+	if o.Namespaced {
+		importNamespace = o.Namespace
+	}
+	serviceImports, err := o.SrcFloater.DynamicClient.Resource(util.ServiceImportGVR).Namespace(importNamespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		klog.Warningf("skip imported services: %v", err)
+	} else {
+		for _, serviceImport := range serviceImports.Items {
+			names = append(names, dnsName{Name: fmt.Sprintf("%s.%s.svc.%s", serviceImport.GetName(), serviceImport.GetNamespace(), o.ClusterDomain)})
+		}
+	}
+
+	namespace, _, _ := splitNamespacedName(o.KosmosDNSService)
+	customerHosts, err := o.SrcFloater.GetCustomerHosts(namespace)
+	if err != nil {
+		klog.Warningf("skip customer hosts: %v", err)
+	}
+	names = append(names, customerHosts...)
+
+	for _, name := range o.Names {
+		names = append(names, dnsName{Name: name})
+	}
+
+	for i := range names {
+		if !strings.HasSuffix(names[i].Name, ".") {
+			names[i].Name += "."
+		}
+	}
+	return names
+}
+
+// GetServiceIP returns the cluster IP of the Service namespace/name.
+func (f *Floater) GetServiceIP(namespacedName string) (string, error) {
+	namespace, name, err := splitNamespacedName(namespacedName)
+	if err != nil {
+		return "", err
+	}
+	svc, err := f.Client.CoreV1().Services(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	if net.ParseIP(svc.Spec.ClusterIP) == nil {
+		return "", fmt.Errorf("service %s has no cluster IP", namespacedName)
+	}
+	return svc.Spec.ClusterIP, nil
+}
+
+// GetCustomerHosts reads the hosts served by the Kosmos CoreDNS installed in namespace, every name must
+// resolve to the IP of its line.
+func (f *Floater) GetCustomerHosts(namespace string) ([]dnsName, error) {
+	cm, err := f.Client.CoreV1().ConfigMaps(namespace).Get(context.TODO(), CustomerHostsConfigMap, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var names []dnsName
+	for _, line := range strings.Split(cm.Data[CustomerHostsKey], "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 || net.ParseIP(fields[0]) == nil {
+			continue
+		}
+		for _, host := range fields[1:] {
+			names = append(names, dnsName{Name: host, Expect: fields[0]})
+		}
+	}
+	return names, nil
+}
+
+func splitNamespacedName(namespacedName string) (string, string, error) {
+	parts := strings.Split(namespacedName, "/")
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return "", "", fmt.Errorf("%q is not a namespace/name", namespacedName)
+	}
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,45 @@
+package floater
+
+import (
+	"testing"
+
+	"github.com/kosmos.io/linkctl/pkg/utils"
+)
+
+func TestDNSPermissions(t *testing.T) {
+	o := &CommandCheckDNSOptions{
+		CommandCheckOptions: &CommandCheckOptions{Namespace: "team-a", ProbeMode: ProbeModeExec},
+		KubeDNSService:      DefaultKubeDNSService,
+		KosmosDNSService:    DefaultKosmosDNSService,
+	}
+
+	namespaces := map[string]bool{}
+	for _, p := range o.requiredPermissions(false, o.dnsPermissions()...) {
+		if p.String() == "get services" {
+			namespaces[p.Namespace] = true
+		}
+	}
+	if !namespaces["kube-system"] || !namespaces[utils.DefaultNamespace] {
+		t.Errorf("services are read in %v, want kube-system and %s", namespaces, utils.DefaultNamespace)
+	}
+
+	for _, namespaced := range []bool{false, true} {
+		o.Namespaced = namespaced
+		for _, p := range o.dnsPermissions() {
+			if p.Resource == "serviceimports" && p.Namespaced != namespaced {
+				t.Errorf("namespaced = %v lists the ServiceImports with %+v", namespaced, p)
+			}
+		}
+	}
+
+	// a tenant can not read kube-system, the dns servers and the customer hosts are skipped instead
+	o.Namespaced = true
+	for _, p := range o.requiredPermissions(false, o.dnsPermissions()...) {
+		if len(p.Namespace) > 0 && p.Namespace != o.Namespace {
+			t.Errorf("namespaced check requires %s in %s, want only %s", p, p.Namespace, o.Namespace)
+		}
+		if !p.Namespaced {
+			t.Errorf("namespaced check requires %s cluster-wide", p)
+		}
+	}
+}
@@ -3,13 +3,20 @@ package floater
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -19,21 +26,143 @@ import (
 	"github.com/kosmos.io/linkctl/pkg/linkctl/floater/command"
 	"github.com/kosmos.io/linkctl/pkg/linkctl/manifest"
 	"github.com/kosmos.io/linkctl/pkg/linkctl/util"
+	"github.com/kosmos.io/linkctl/pkg/mesh"
+	"github.com/kosmos.io/linkctl/pkg/probe"
 	"github.com/kosmos.io/linkctl/pkg/utils"
 )
 
 type Protocol string
 
 const (
+	ICMP Protocol = "icmp"
 	TCP  Protocol = "tcp"
 	UDP  Protocol = "udp"
+	HTTP Protocol = "http"
 	IPv4 Protocol = "ipv4"
+	// MTU discovers the path MTU with don't-fragment pings
+	MTU Protocol = "mtu"
+	// DNS resolves a name, it is only used by check dns
+	DNS Protocol = "dns"
 )
 
+var SupportedProtocols = []Protocol{ICMP, TCP, UDP, HTTP, MTU}
+
+func IsSupportedProtocol(protocol string) bool {
+	for _, p := range SupportedProtocols {
+		if string(p) == protocol {
+			return true
+		}
+	}
+	// ipv4 is kept as an alias of icmp for old config files
+	return Protocol(protocol) == IPv4
+}
+
+type ProbeOptions struct {
+	Port    string
+	Timeout int
+
+	Count      int
+	Interval   float64
+	PacketSize int
+
+	// DNSServer resolves the target of the dns probe, whose answer must contain Expect when it is set
+	DNSServer string
+	Expect    string
+}
+
+const (
+	ProbeModeExec = "exec"
+	ProbeModeAPI  = "api"
+)
+
+// batchExecMargin is added to the cmd timeout of a batch exec session, which starts all of its probes at once.
+const batchExecMargin = 5 * time.Second
+
+// NewProbeRequest builds the request of the floater probe API used to check targetIP with the given protocol.
+func NewProbeRequest(protocol Protocol, targetIP string, opts ProbeOptions) *probe.Request {
+	if protocol == IPv4 {
+		protocol = ICMP
+	}
+	if protocol == DNS {
+		return &probe.Request{
+			Protocol: string(protocol),
+			TargetIP: opts.DNSServer,
+			Timeout:  opts.Timeout,
+			Name:     targetIP,
+			Expect:   opts.Expect,
+		}
+	}
+	return &probe.Request{
+		Protocol:   string(protocol),
+		TargetIP:   targetIP,
+		Port:       opts.Port,
+		Timeout:    opts.Timeout,
+		Count:      opts.Count,
+		Interval:   opts.Interval,
+		PacketSize: opts.PacketSize,
+	}
+}
+
+// NewCommand builds the probe used to check targetIP with the given protocol.
+func NewCommand(protocol Protocol, targetIP string, opts ProbeOptions) (command.Command, error) {
+	switch protocol {
+	case ICMP, IPv4:
+		return &command.Ping{
+			TargetIP:   targetIP,
+			Count:      opts.Count,
+			Interval:   opts.Interval,
+			PacketSize: opts.PacketSize,
+			Timeout:    opts.Timeout,
+		}, nil
+	case TCP:
+		return &command.TCP{
+			TargetIP: targetIP,
+			Port:     opts.Port,
+			Timeout:  opts.Timeout,
+		}, nil
+	case UDP:
+		return &command.UDP{
+			TargetIP: targetIP,
+			Port:     opts.Port,
+			Timeout:  opts.Timeout,
+		}, nil
+	case HTTP:
+		return &command.Curl{
+			TargetIP: targetIP,
+			Port:     opts.Port,
+			Timeout:  opts.Timeout,
+		}, nil
+	case MTU:
+		return &command.MTU{
+			TargetIP: targetIP,
+			Timeout:  opts.Timeout,
+		}, nil
+	case DNS:
+		return &command.DNS{
+			Server:  opts.DNSServer,
+			Name:    targetIP,
+			Expect:  opts.Expect,
+			Timeout: opts.Timeout,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported protocol %q", protocol)
+	}
+}
+
 const (
 	DefaultFloaterName = "clusterlink-floater"
+	// DefaultHostFloaterName is the hostNetwork floater, it runs side by side with the pod network one
+	DefaultHostFloaterName = DefaultFloaterName + "-host"
 )
 
+// FloaterName returns the name of the floater running in the host or in the pod network.
+func FloaterName(hostNetwork bool) string {
+	if hostNetwork {
+		return DefaultHostFloaterName
+	}
+	return DefaultFloaterName
+}
+
 type FloatInfo struct {
 	NodeName string
 	NodeIPs  []string
@@ -49,19 +178,32 @@ func (i *FloatInfo) String() string {
 type Floater struct {
 	Namespace         string
 	Name              string
+	RunID             string
+	Owner             string
 	ImageRepository   string
 	Version           string
 	PodWaitTime       int
 	Port              string
 	EnableHostNetwork bool
 	EnableAnalysis    bool
+	// EnableProbeAPI serves the probe API of --probe-mode api
+	EnableProbeAPI bool
+
+	// Namespaced floaters run in an existing namespace and read no cluster-wide object
+	Namespaced bool
+	// Selection picks the nodes of the floaters, it is shared with the floaters of the other network
+	Selection *NodeSelection
 
 	CIDRsMap map[string]string
 
-	Config *rest.Config
-	Client kubernetes.Interface
+	Config        *rest.Config
+	Client        kubernetes.Interface
+	DynamicClient dynamic.Interface
 
 	CmdTimeout int
+
+	// MeshConfigMap is set when the floaters run as mesh agents
+	MeshConfigMap string
 }
 
 func NewCheckFloater(o *CommandCheckOptions, isDst bool) *Floater {
@@ -71,13 +213,18 @@ func NewCheckFloater(o *CommandCheckOptions, isDst bool) *Floater {
 	}
 	floater := &Floater{
 		Namespace:         o.Namespace,
-		Name:              DefaultFloaterName,
+		Name:              runScopedName(FloaterName(o.HostNetwork), o.RunID),
+		RunID:             o.RunID,
+		Owner:             RunOwner(),
 		ImageRepository:   imageRepository,
 		Version:           o.Version,
 		PodWaitTime:       o.PodWaitTime,
 		Port:              o.Port,
 		EnableHostNetwork: false,
-		EnableAnalysis:    false,
+		EnableAnalysis:    o.EnableAnalysis,
+		EnableProbeAPI:    o.ProbeMode == ProbeModeAPI,
+		Namespaced:        o.Namespaced,
+		Selection:         o.nodeSelection(),
 		CmdTimeout:        o.CmdTimeout,
 	}
 	if o.HostNetwork {
@@ -86,11 +233,25 @@ func NewCheckFloater(o *CommandCheckOptions, isDst bool) *Floater {
 	return floater
 }
 
-func (f *Floater) completeFromKubeConfigPath(kubeConfigPath string) error {
-	config, err := clientcmd.BuildConfigFromFlags("", kubeConfigPath)
+// WithHostNetwork returns the floater of the same cluster running in the host or in the pod network,
+// it shares the clients of f.
+func (f *Floater) WithHostNetwork(hostNetwork bool) *Floater {
+	floater := *f
+	floater.Name = runScopedName(FloaterName(hostNetwork), f.RunID)
+	floater.EnableHostNetwork = hostNetwork
+	return &floater
+}
+
+func (f *Floater) completeFromClientConfig(clientConfig clientcmd.ClientConfig) error {
+	config, err := clientConfig.ClientConfig()
 	if err != nil {
 		return fmt.Errorf("linkctl docter complete error, generate floater config failed: %v", err)
 	}
+	return f.completeFromConfig(config)
+}
+
+func (f *Floater) completeFromConfig(config *rest.Config) error {
+	var err error
 	f.Config = config
 
 	f.Client, err = kubernetes.NewForConfig(f.Config)
@@ -98,98 +259,250 @@ func (f *Floater) completeFromKubeConfigPath(kubeConfigPath string) error {
 		return fmt.Errorf("linkctl docter complete error, generate floater client failed: %v", err)
 	}
 
+	f.DynamicClient, err = dynamic.NewForConfig(f.Config)
+	if err != nil {
+		return fmt.Errorf("linkctl docter complete error, generate floater dynamic client failed: %v", err)
+	}
+
 	return nil
 }
 
-func (f *Floater) CreateFloater() error {
-	klog.Infof("create Clusterlink floater, namespace: %s", f.Namespace)
-	namespace := &corev1.Namespace{}
-	namespace.Name = f.Namespace
-	_, err := f.Client.CoreV1().Namespaces().Create(context.TODO(), namespace, metav1.CreateOptions{})
+// GetClusterCIDRsMap reads spec.clusterLinkOptions.globalCIDRsMap of the Kosmos cluster object clusterName.
+func (f *Floater) GetClusterCIDRsMap(clusterName string) (map[string]string, error) {
+	cluster, err := f.DynamicClient.Resource(util.ClusterGVR).Get(context.TODO(), clusterName, metav1.GetOptions{})
 	if err != nil {
-		if !apierrors.IsAlreadyExists(err) {
-			return fmt.Errorf("linkctl floater run error, namespace options failed: %v", err)
+		return nil, err
+	}
+
+	return clusterCIDRsMap(cluster)
+}
+
+func clusterCIDRsMap(cluster *unstructured.Unstructured) (map[string]string, error) {
+	cidrsMap, _, err := unstructured.NestedStringMap(cluster.Object, "spec", "clusterLinkOptions", "globalCIDRsMap")
+	if err != nil {
+		return nil, fmt.Errorf("parse globalCIDRsMap of cluster %s failed: %v", cluster.GetName(), err)
+	}
+
+	return cidrsMap, nil
+}
+
+func (f *Floater) CreateFloater() error {
+	if f.EnableHostNetwork {
+		if err := f.checkHostPort(); err != nil {
+			return err
 		}
 	}
 
-	klog.Info("create Clusterlink floater, apply RBAC")
-	if err = f.applyServiceAccount(); err != nil {
+	klog.Infof("create Clusterlink floater, namespace: %s", f.Namespace)
+	if err := f.applyNamespace(); err != nil {
 		return err
 	}
-	if err = f.applyClusterRole(); err != nil {
+
+	klog.Info("create Clusterlink floater, apply RBAC")
+	if err := f.applyServiceAccount(); err != nil {
 		return err
 	}
-	if err = f.applyClusterRoleBinding(); err != nil {
-		return err
+	if len(f.MeshConfigMap) > 0 {
+		if err := f.applyRole(nil); err != nil {
+			return err
+		}
+		if err := f.applyRoleBinding(); err != nil {
+			return err
+		}
 	}
 
 	klog.Infof("create Clusterlink floater, version: %s", f.Version)
-	if err = f.applyDaemonSet(); err != nil {
+	if err := f.applyDaemonSet(); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// applyNamespace creates the namespace, a namespace left terminating by a previous clean is waited for first.
+func (f *Floater) applyNamespace() error {
+	// the namespace is owned by the tenant, a missing one fails the creation of the ServiceAccount
+	if f.Namespaced {
+		return nil
+	}
+
+	existing, err := f.Client.CoreV1().Namespaces().Get(context.TODO(), f.Namespace, metav1.GetOptions{})
+	if err == nil && existing.DeletionTimestamp == nil {
+		return nil
+	}
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("linkctl floater run error, namespace options failed: %v", err)
+	}
+	if err == nil {
+		klog.Infof("namespace %s is terminating, wait for it to be removed", f.Namespace)
+		pollErr := wait.PollImmediate(time.Second, time.Duration(f.PodWaitTime)*time.Second, func() (bool, error) {
+			_, err := f.Client.CoreV1().Namespaces().Get(context.TODO(), f.Namespace, metav1.GetOptions{})
+			return apierrors.IsNotFound(err), nil
+		})
+		if pollErr != nil {
+			return fmt.Errorf("linkctl floater run error, namespace %s is still terminating: %v", f.Namespace, pollErr)
+		}
+	}
+
+	namespace := &corev1.Namespace{}
+	namespace.Name = f.Namespace
+	_, err = f.Client.CoreV1().Namespaces().Create(context.TODO(), namespace, metav1.CreateOptions{})
+	if err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("linkctl floater run error, namespace options failed: %v", err)
+		}
+	}
+
+	return nil
+}
+
 func (f *Floater) applyServiceAccount() error {
 	clusterlinkFloaterServiceAccount, err := util.GenerateServiceAccount(manifest.ClusterlinkFloaterServiceAccount, manifest.ServiceAccountReplace{
 		Namespace: f.Namespace,
+		Name:      f.rbacName(),
+		RunID:     f.RunID,
+		Owner:     f.Owner,
 	})
 	if err != nil {
 		return err
 	}
 	_, err = f.Client.CoreV1().ServiceAccounts(f.Namespace).Create(context.TODO(), clusterlinkFloaterServiceAccount, metav1.CreateOptions{})
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("linkctl floater run error, serviceaccount options failed: %v", err)
+	}
+
+	existing, err := f.Client.CoreV1().ServiceAccounts(f.Namespace).Get(context.TODO(), clusterlinkFloaterServiceAccount.Name, metav1.GetOptions{})
 	if err != nil {
-		if !apierrors.IsAlreadyExists(err) {
-			return fmt.Errorf("linkctl floater run error, serviceaccount options failed: %v", err)
-		}
+		return fmt.Errorf("linkctl floater run error, serviceaccount options failed: %v", err)
+	}
+	if equality.Semantic.DeepEqual(existing.AutomountServiceAccountToken, clusterlinkFloaterServiceAccount.AutomountServiceAccountToken) {
+		return nil
+	}
+	klog.Infof("update serviceaccount %s/%s, automountServiceAccountToken changed", f.Namespace, existing.Name)
+	existing.AutomountServiceAccountToken = clusterlinkFloaterServiceAccount.AutomountServiceAccountToken
+	if _, err = f.Client.CoreV1().ServiceAccounts(f.Namespace).Update(context.TODO(), existing, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("linkctl floater run error, serviceaccount options failed: %v", err)
 	}
 
 	return nil
 }
 
-func (f *Floater) applyClusterRole() error {
-	clusterlinkFloaterClusterRole, err := util.GenerateClusterRole(manifest.ClusterlinkFloaterClusterRole, nil)
+// applyRole lets the mesh agents read their config and publish their status, the floaters of a check do not
+// talk to the API server and get no role. Only the ConfigMaps of the mesh can be read and updated, the status
+// ConfigMaps are named after the peers, which are only known once the floaters run.
+func (f *Floater) applyRole(statusConfigMaps []string) error {
+	clusterlinkFloaterRole, err := util.GenerateRole(manifest.ClusterlinkFloaterRole, manifest.RoleReplace{
+		Namespace:        f.Namespace,
+		Name:             f.rbacName(),
+		RunID:            f.RunID,
+		Owner:            f.Owner,
+		ConfigMap:        f.MeshConfigMap,
+		StatusConfigMaps: statusConfigMaps,
+	})
 	if err != nil {
 		return err
 	}
-	_, err = f.Client.RbacV1().ClusterRoles().Create(context.TODO(), clusterlinkFloaterClusterRole, metav1.CreateOptions{})
+	_, err = f.Client.RbacV1().Roles(f.Namespace).Create(context.TODO(), clusterlinkFloaterRole, metav1.CreateOptions{})
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("linkctl floater run error, role options failed: %v", err)
+	}
+
+	existing, err := f.Client.RbacV1().Roles(f.Namespace).Get(context.TODO(), clusterlinkFloaterRole.Name, metav1.GetOptions{})
 	if err != nil {
-		if !apierrors.IsAlreadyExists(err) {
-			return fmt.Errorf("linkctl floater run error, clusterrole options failed: %v", err)
-		}
+		return fmt.Errorf("linkctl floater run error, role options failed: %v", err)
+	}
+	if equality.Semantic.DeepEqual(existing.Rules, clusterlinkFloaterRole.Rules) {
+		return nil
+	}
+	klog.Infof("update role %s/%s, rules changed", f.Namespace, existing.Name)
+	existing.Rules = clusterlinkFloaterRole.Rules
+	if _, err = f.Client.RbacV1().Roles(f.Namespace).Update(context.TODO(), existing, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("linkctl floater run error, role options failed: %v", err)
 	}
 
 	return nil
 }
 
-func (f *Floater) applyClusterRoleBinding() error {
-	clusterlinkFloaterClusterRoleBinding, err := util.GenerateClusterRoleBinding(manifest.ClusterlinkFloaterClusterRoleBinding, manifest.ClusterRoleBindingReplace{
+func (f *Floater) applyRoleBinding() error {
+	clusterlinkFloaterRoleBinding, err := util.GenerateRoleBinding(manifest.ClusterlinkFloaterRoleBinding, manifest.RoleBindingReplace{
 		Namespace: f.Namespace,
+		Name:      f.rbacName(),
+		RunID:     f.RunID,
+		Owner:     f.Owner,
 	})
 	if err != nil {
 		return err
 	}
-	_, err = f.Client.RbacV1().ClusterRoleBindings().Create(context.TODO(), clusterlinkFloaterClusterRoleBinding, metav1.CreateOptions{})
+	_, err = f.Client.RbacV1().RoleBindings(f.Namespace).Create(context.TODO(), clusterlinkFloaterRoleBinding, metav1.CreateOptions{})
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("linkctl floater run error, rolebinding options failed: %v", err)
+	}
+
+	existing, err := f.Client.RbacV1().RoleBindings(f.Namespace).Get(context.TODO(), clusterlinkFloaterRoleBinding.Name, metav1.GetOptions{})
 	if err != nil {
-		if !apierrors.IsAlreadyExists(err) {
-			return fmt.Errorf("linkctl floater run error, clusterrolebinding options failed: %v", err)
+		return fmt.Errorf("linkctl floater run error, rolebinding options failed: %v", err)
+	}
+	// the role of a binding is immutable, the binding is recreated
+	if !equality.Semantic.DeepEqual(existing.RoleRef, clusterlinkFloaterRoleBinding.RoleRef) {
+		klog.Infof("recreate rolebinding %s/%s, roleRef changed", f.Namespace, existing.Name)
+		if err = f.Client.RbacV1().RoleBindings(f.Namespace).Delete(context.TODO(), existing.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("linkctl floater run error, rolebinding options failed: %v", err)
 		}
+		if _, err = f.Client.RbacV1().RoleBindings(f.Namespace).Create(context.TODO(), clusterlinkFloaterRoleBinding, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("linkctl floater run error, rolebinding options failed: %v", err)
+		}
+		return nil
+	}
+	if equality.Semantic.DeepEqual(existing.Subjects, clusterlinkFloaterRoleBinding.Subjects) {
+		return nil
+	}
+	klog.Infof("update rolebinding %s/%s, subjects changed", f.Namespace, existing.Name)
+	existing.Subjects = clusterlinkFloaterRoleBinding.Subjects
+	if _, err = f.Client.RbacV1().RoleBindings(f.Namespace).Update(context.TODO(), existing, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("linkctl floater run error, rolebinding options failed: %v", err)
 	}
 
 	return nil
 }
 
+func (f *Floater) daemonSetReplace() manifest.DaemonSetReplace {
+	return manifest.DaemonSetReplace{
+		Namespace:          f.Namespace,
+		Name:               f.Name,
+		Version:            f.Version,
+		ImageRepository:    f.ImageRepository,
+		Port:               f.Port,
+		ServiceAccountName: f.rbacName(),
+		RunID:              f.RunID,
+		Owner:              f.Owner,
+		EnableHostNetwork:  f.EnableHostNetwork,
+		EnableAnalysis:     f.EnableAnalysis,
+		EnableProbeAPI:     f.EnableProbeAPI,
+		MeshConfigMap:      f.MeshConfigMap,
+		ExcludeLabel:       ExcludeLabel,
+		NodeSelector:       f.Selection.nodeSelector(),
+		Nodes:              f.Selection.scheduledNodes(),
+		ExcludeNodes:       f.Selection.excludedNodes(),
+	}
+}
+
+// applyDaemonSet creates the floater DaemonSet, an existing DaemonSet whose spec drifted from the desired one,
+// for example after a version, port or host network change, is updated and its rollout waited for.
 func (f *Floater) applyDaemonSet() error {
-	clusterlinkFloaterDaemonSet, err := util.GenerateDaemonSet(manifest.ClusterlinkFloaterDaemonSet, manifest.DaemonSetReplace{
-		Namespace:         f.Namespace,
-		Name:              f.Name,
-		Version:           f.Version,
-		ImageRepository:   f.ImageRepository,
-		Port:              f.Port,
-		EnableHostNetwork: f.EnableHostNetwork,
-		EnableAnalysis:    f.EnableAnalysis,
-	})
+	if err := f.resolveNodes(); err != nil {
+		return err
+	}
+	replace := f.daemonSetReplace()
+	clusterlinkFloaterDaemonSet, err := util.GenerateDaemonSet(manifest.ClusterlinkFloaterDaemonSet, replace)
 	if err != nil {
 		return err
 	}
@@ -198,6 +511,9 @@ func (f *Floater) applyDaemonSet() error {
 		if !apierrors.IsAlreadyExists(err) {
 			return fmt.Errorf("linkctl floater run error, daemonset options failed: %v", err)
 		}
+		if err = f.updateDaemonSet(replace, clusterlinkFloaterDaemonSet); err != nil {
+			return err
+		}
 	}
 
 	floaterLabel := map[string]string{"app": f.Name}
@@ -208,6 +524,106 @@ func (f *Floater) applyDaemonSet() error {
 	return nil
 }
 
+func (f *Floater) updateDaemonSet(replace manifest.DaemonSetReplace, desired *appsv1.DaemonSet) error {
+	existing, err := f.Client.AppsV1().DaemonSets(f.Namespace).Get(context.Background(), desired.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("linkctl floater run error, daemonset options failed: %v", err)
+	}
+	// a check keeps the agents of a running mesh
+	if meshConfigMap := containerEnv(existing, mesh.EnvConfigMap); len(replace.MeshConfigMap) == 0 && len(meshConfigMap) > 0 {
+		replace.MeshConfigMap = meshConfigMap
+		// parsed like the floater does
+		enableAnalysis, _ := strconv.ParseBool(containerEnv(existing, mesh.EnvEnableAnalysis))
+		replace.EnableAnalysis = replace.EnableAnalysis || enableAnalysis
+		if desired, err = util.GenerateDaemonSet(manifest.ClusterlinkFloaterDaemonSet, replace); err != nil {
+			return err
+		}
+	}
+
+	drift := daemonSetDrift(existing, desired)
+	if len(drift) == 0 {
+		return nil
+	}
+	klog.Infof("update daemonset %s/%s, %s", f.Namespace, desired.Name, strings.Join(drift, ", "))
+	desired.ResourceVersion = existing.ResourceVersion
+	if _, err = f.Client.AppsV1().DaemonSets(f.Namespace).Update(context.Background(), desired, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("linkctl floater run error, daemonset options failed: %v", err)
+	}
+	if err = util.WaitDaemonSetReady(f.Client, f.Namespace, desired.Name, f.PodWaitTime); err != nil {
+		klog.Warningf("exist cluster node update floater timeout, error: %v", err)
+	}
+
+	return nil
+}
+
+// daemonSetDrift lists the differences of the pod template that make an existing DaemonSet outdated.
+func daemonSetDrift(existing, desired *appsv1.DaemonSet) []string {
+	var drift []string
+	existingSpec, desiredSpec := existing.Spec.Template.Spec, desired.Spec.Template.Spec
+	if existingSpec.HostNetwork != desiredSpec.HostNetwork {
+		drift = append(drift, fmt.Sprintf("hostNetwork %t -> %t", existingSpec.HostNetwork, desiredSpec.HostNetwork))
+	}
+	if !equality.Semantic.DeepEqual(existingSpec.AutomountServiceAccountToken, desiredSpec.AutomountServiceAccountToken) {
+		drift = append(drift, "automountServiceAccountToken changed")
+	}
+	if !equality.Semantic.DeepEqual(existingSpec.NodeSelector, desiredSpec.NodeSelector) {
+		drift = append(drift, "nodeSelector changed")
+	}
+	if !equality.Semantic.DeepEqual(existingSpec.Affinity, desiredSpec.Affinity) {
+		drift = append(drift, "affinity changed")
+	}
+	if existingSpec.ServiceAccountName != desiredSpec.ServiceAccountName {
+		drift = append(drift, fmt.Sprintf("serviceAccountName %s -> %s", existingSpec.ServiceAccountName, desiredSpec.ServiceAccountName))
+	}
+	if !equality.Semantic.DeepEqual(existing.Spec.Template.Annotations, desired.Spec.Template.Annotations) {
+		drift = append(drift, "annotations changed")
+	}
+
+	existingContainers := map[string]corev1.Container{}
+	for _, c := range existingSpec.Containers {
+		existingContainers[c.Name] = c
+	}
+	for _, c := range desiredSpec.Containers {
+		e, ok := existingContainers[c.Name]
+		if !ok {
+			drift = append(drift, fmt.Sprintf("container %s added", c.Name))
+			continue
+		}
+		if e.Image != c.Image {
+			drift = append(drift, fmt.Sprintf("image %s -> %s", e.Image, c.Image))
+		}
+		existingEnv := map[string]string{}
+		for _, env := range e.Env {
+			existingEnv[env.Name] = env.Value
+		}
+		for _, env := range c.Env {
+			if env.ValueFrom != nil {
+				continue
+			}
+			if value, ok := existingEnv[env.Name]; !ok || value != env.Value {
+				drift = append(drift, fmt.Sprintf("env %s %q -> %q", env.Name, value, env.Value))
+			}
+		}
+	}
+	if len(existingSpec.Containers) != len(desiredSpec.Containers) {
+		drift = append(drift, fmt.Sprintf("%d containers -> %d", len(existingSpec.Containers), len(desiredSpec.Containers)))
+	}
+	return drift
+}
+
+// containerEnv returns the value of an env of the first container.
+func containerEnv(ds *appsv1.DaemonSet, name string) string {
+	if len(ds.Spec.Template.Spec.Containers) == 0 {
+		return ""
+	}
+	for _, env := range ds.Spec.Template.Spec.Containers[0].Env {
+		if env.Name == name {
+			return env.Value
+		}
+	}
+	return ""
+}
+
 func (f *Floater) GetPodInfo() ([]*FloatInfo, error) {
 	selector := util.MapToString(map[string]string{"app": f.Name})
 	pods, err := f.Client.CoreV1().Pods(f.Namespace).List(context.TODO(), metav1.ListOptions{
@@ -223,6 +639,10 @@ func (f *Floater) GetPodInfo() ([]*FloatInfo, error) {
 
 	var floaterInfos []*FloatInfo
 	for _, pod := range pods.Items {
+		// pods replaced by a rollout keep their IPs until they are gone
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
 		podInfo := &FloatInfo{
 			NodeName: pod.Spec.NodeName,
 			PodName:  pod.GetObjectMeta().GetName(),
@@ -256,6 +676,9 @@ func (f *Floater) GetNodesInfo() ([]*FloatInfo, error) {
 	if len(pods.Items) == 0 {
 		return nil, fmt.Errorf("no pods in %s with selector %s", f.Namespace, selector)
 	}
+	if f.Namespaced {
+		return podNodesInfo(pods.Items), nil
+	}
 
 	nodes, err := f.Client.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
 	if err != nil {
@@ -267,6 +690,9 @@ func (f *Floater) GetNodesInfo() ([]*FloatInfo, error) {
 
 	var floaterInfos []*FloatInfo
 	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
 		for _, node := range nodes.Items {
 			if pod.Spec.NodeName == node.Name {
 				nodeInfo := &FloatInfo{
@@ -282,6 +708,36 @@ func (f *Floater) GetNodesInfo() ([]*FloatInfo, error) {
 	return floaterInfos, nil
 }
 
+// podNodesInfo identifies the nodes of the floaters without reading the Node objects, by the spec.nodeName
+// and the status.hostIPs of the pods, the same fields the downward API exposes to the floaters.
+func podNodesInfo(pods []corev1.Pod) []*FloatInfo {
+	var floaterInfos []*FloatInfo
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil || len(pod.Spec.NodeName) == 0 {
+			continue
+		}
+		floaterInfos = append(floaterInfos, &FloatInfo{
+			NodeName: pod.Spec.NodeName,
+			NodeIPs:  hostIPToArray(pod.Status),
+			PodName:  pod.Name,
+		})
+	}
+	return floaterInfos
+}
+
+func hostIPToArray(status corev1.PodStatus) []string {
+	var hostIPs []string
+
+	for _, hostIP := range status.HostIPs {
+		hostIPs = append(hostIPs, hostIP.IP)
+	}
+	if len(hostIPs) == 0 && len(status.HostIP) > 0 {
+		hostIPs = append(hostIPs, status.HostIP)
+	}
+
+	return hostIPs
+}
+
 func nodeIPToArray(node corev1.Node) []string {
 	var nodeIPs []string
 
@@ -294,6 +750,15 @@ func nodeIPToArray(node corev1.Node) []string {
 	return nodeIPs
 }
 
+// GetProbeTimeout leaves one second for the exec session so that a probe timeout is reported as failed.
+func (f *Floater) GetProbeTimeout() int {
+	timeout := int(f.GetCmdTimeout().Seconds()) - 1
+	if timeout < 1 {
+		return 1
+	}
+	return timeout
+}
+
 func (f *Floater) GetCmdTimeout() time.Duration {
 	if f.CmdTimeout == 0 {
 		return 3 * time.Second
@@ -303,6 +768,57 @@ func (f *Floater) GetCmdTimeout() time.Duration {
 }
 
 func (f *Floater) CommandExec(fInfo *FloatInfo, cmd command.Command) *command.Result {
+	start := time.Now()
+	out, err := f.stream(fInfo, cmd.GetCommandStr(), f.GetCmdTimeout())
+	elapsed := time.Since(start)
+
+	var result *command.Result
+	if err != nil {
+		// klog.Infof("error: %s", err)
+		result = command.ParseError(err)
+	} else {
+		result = cmd.ParseResult(out)
+	}
+	if result.Elapsed == 0 {
+		result.Elapsed = elapsed
+	}
+
+	return result
+}
+
+// BatchCommandExec runs all commands concurrently in a single exec session of the floater, the
+// results are returned in the order of the commands.
+func (f *Floater) BatchCommandExec(fInfo *FloatInfo, cmds []command.Command) []*command.Result {
+	if len(cmds) == 0 {
+		return nil
+	}
+
+	// the commands run side by side, leave some room for starting them all
+	start := time.Now()
+	out, err := f.stream(fInfo, command.GetBatchCommandStr(cmds), f.GetCmdTimeout()+batchExecMargin)
+	elapsed := time.Since(start)
+
+	// the probes share the session, so every one of them gets its elapsed time
+	var results []*command.Result
+	if err != nil {
+		results = make([]*command.Result, 0, len(cmds))
+		for range cmds {
+			results = append(results, command.ParseError(err))
+		}
+	} else {
+		results = command.ParseBatchResult(cmds, out)
+	}
+	for _, result := range results {
+		if result.Elapsed == 0 {
+			result.Elapsed = elapsed
+		}
+	}
+
+	return results
+}
+
+// stream feeds cmdStr to a shell in the floater and returns its stdout.
+func (f *Floater) stream(fInfo *FloatInfo, cmdStr string, timeout time.Duration) (string, error) {
 	req := f.Client.CoreV1().RESTClient().Post().Resource("pods").Namespace(f.Namespace).Name(fInfo.PodName).
 		SubResource("exec").
 		Param("container", "floater").
@@ -317,13 +833,11 @@ func (f *Floater) CommandExec(fInfo *FloatInfo, cmd command.Command) *command.Re
 
 	exec, err := remotecommand.NewSPDYExecutor(f.Config, "POST", req.URL())
 	if err != nil {
-		return command.ParseError(err)
+		return "", err
 	}
 
-	// timeout 5s
-	ctx, cancel := context.WithTimeout(context.Background(), f.GetCmdTimeout())
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
-	cmdStr := cmd.GetCommandStr()
 
 	// klog.Infof("cmdStr: %s", cmdStr)
 	err = exec.StreamWithContext(ctx, remotecommand.StreamOptions{
@@ -332,35 +846,135 @@ func (f *Floater) CommandExec(fInfo *FloatInfo, cmd command.Command) *command.Re
 		Stderr: errBuffer,
 		Tty:    false,
 	})
+	if err != nil {
+		return "", fmt.Errorf("%s, stderr: %s", err, errBuffer.String())
+	}
+
+	return outBuffer.String(), nil
+}
+
+// CommandProxy asks the floater to run the probe natively through its probe API, the request goes
+// through the pod proxy of the API server.
+func (f *Floater) CommandProxy(fInfo *FloatInfo, req *probe.Request) *command.Result {
+	ctx, cancel := context.WithTimeout(context.Background(), f.GetCmdTimeout())
+	defer cancel()
 
+	start := time.Now()
+	body, err := f.Client.CoreV1().Pods(f.Namespace).ProxyGet("http", fInfo.PodName, f.Port, probe.Path, req.Params()).DoRaw(ctx)
+	elapsed := time.Since(start)
 	if err != nil {
-		// klog.Infof("error: %s", err)
-		return command.ParseError(fmt.Errorf("%s, stderr: %s", err, errBuffer.String()))
+		return command.ParseError(fmt.Errorf("%s, body: %s", err, body))
+	}
+
+	result := &command.Result{}
+	if err = json.Unmarshal(body, result); err != nil {
+		return command.ParseError(fmt.Errorf("decode probe result failed: %s, body: %s", err, body))
+	}
+	if result.Elapsed == 0 {
+		result.Elapsed = elapsed
 	}
 
-	return cmd.ParseResult(outBuffer.String())
+	return result
 }
 
+// ApplyMeshConfig publishes the peers and the probe settings read by the mesh agents, the Role of the agents
+// lets every peer update its status.
+func (f *Floater) ApplyMeshConfig(config *mesh.Config) error {
+	var statusConfigMaps []string
+	for _, peer := range config.Peers {
+		statusConfigMaps = append(statusConfigMaps, mesh.StatusConfigMapName(f.MeshConfigMap, peer.NodeName))
+	}
+	if err := f.applyRole(statusConfigMaps); err != nil {
+		return err
+	}
+
+	cm, err := mesh.NewConfigMap(f.Namespace, f.MeshConfigMap, f.MeshConfigMap, mesh.ConfigKey, config)
+	if err != nil {
+		return err
+	}
+	_, err = f.Client.CoreV1().ConfigMaps(f.Namespace).Create(context.TODO(), cm, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		_, err = f.Client.CoreV1().ConfigMaps(f.Namespace).Update(context.TODO(), cm, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("linkctl floater run error, configmap options failed: %v", err)
+	}
+
+	return nil
+}
+
+// GetMeshStatus reads the config of the mesh and the latest status published by every agent.
+func (f *Floater) GetMeshStatus() (*mesh.Config, []*mesh.Status, error) {
+	cms, err := f.Client.CoreV1().ConfigMaps(f.Namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: util.MapToString(map[string]string{mesh.Label: f.MeshConfigMap}),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var config *mesh.Config
+	var statuses []*mesh.Status
+	for i := range cms.Items {
+		cm := &cms.Items[i]
+		if cm.Name == f.MeshConfigMap {
+			config = &mesh.Config{}
+			if err = mesh.ParseConfigMap(cm, mesh.ConfigKey, config); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+		status := &mesh.Status{}
+		if err = mesh.ParseConfigMap(cm, mesh.StatusKey, status); err != nil {
+			klog.Warningf("skip mesh status: %v", err)
+			continue
+		}
+		statuses = append(statuses, status)
+	}
+	if config == nil {
+		return nil, nil, fmt.Errorf("no mesh %s in namespace %s, run linkctl mesh first", f.MeshConfigMap, f.Namespace)
+	}
+
+	return config, statuses, nil
+}
+
+// RemoveFloater removes the objects of the run of f, the namespace is kept while the floaters of other runs
+// are left in it.
 func (f *Floater) RemoveFloater() error {
-	klog.Infof("remove Clusterlink floater, version: %s", f.Version)
+	klog.Infof("remove Clusterlink floater, version: %s, run: %s", f.Version, f.RunID)
 	if err := f.removeDaemonSet(); err != nil {
 		return err
 	}
 
+	if err := f.removeMeshConfigMaps(); err != nil {
+		return err
+	}
+	// left behind by an interrupted check --services
+	if err := f.RemoveService(); err != nil {
+		return err
+	}
+
 	klog.Info("remove Clusterlink floater, apply RBAC")
-	if err := f.removeClusterRoleBinding(); err != nil {
+	if err := f.removeRoleBinding(); err != nil {
 		return err
 	}
-	if err := f.removeClusterRole(); err != nil {
+	if err := f.removeRole(); err != nil {
 		return err
 	}
 	if err := f.removeServiceAccount(); err != nil {
 		return err
 	}
 
-	if f.Namespace != utils.DefaultNamespace {
+	if f.Namespace != utils.DefaultNamespace && !f.Namespaced {
+		runs, err := f.otherRuns()
+		if err != nil {
+			return fmt.Errorf("linkctl floater run error, daemonset options failed: %v", err)
+		}
+		if len(runs) > 0 {
+			klog.Infof("keep namespace %s, it is used by the runs %s", f.Namespace, strings.Join(runs, ", "))
+			return nil
+		}
 		klog.Infof("remove namespace specified when creating Clusterlink floater, namespace: %s", f.Namespace)
-		err := f.Client.CoreV1().Namespaces().Delete(context.TODO(), f.Namespace, metav1.DeleteOptions{})
+		err = f.Client.CoreV1().Namespaces().Delete(context.TODO(), f.Namespace, metav1.DeleteOptions{})
 		if err != nil {
 			if !apierrors.IsNotFound(err) {
 				return fmt.Errorf("linkctl floater run error, namespace options failed: %v", err)
@@ -371,33 +985,87 @@ func (f *Floater) RemoveFloater() error {
 	return nil
 }
 
+// removeDaemonSet removes the floaters of the run in both the pod and the host network.
 func (f *Floater) removeDaemonSet() error {
-	err := f.Client.AppsV1().DaemonSets(f.Namespace).Delete(context.Background(), f.Name, metav1.DeleteOptions{})
+	for _, name := range []string{runScopedName(DefaultFloaterName, f.RunID), runScopedName(DefaultHostFloaterName, f.RunID)} {
+		err := f.Client.AppsV1().DaemonSets(f.Namespace).Delete(context.Background(), name, metav1.DeleteOptions{})
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				return fmt.Errorf("linkctl floater run error, daemonset options failed: %v", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// removeMeshConfigMaps removes the config and the status ConfigMaps of the mesh of the run.
+func (f *Floater) removeMeshConfigMaps() error {
+	err := f.Client.CoreV1().ConfigMaps(f.Namespace).DeleteCollection(context.Background(), metav1.DeleteOptions{}, metav1.ListOptions{
+		LabelSelector: util.MapToString(map[string]string{mesh.Label: MeshName(f.RunID)}),
+	})
 	if err != nil {
 		if !apierrors.IsNotFound(err) {
-			return fmt.Errorf("linkctl floater run error, daemonset options failed: %v", err)
+			return fmt.Errorf("linkctl floater run error, configmap options failed: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func (f *Floater) removeRoleBinding() error {
+	err := f.Client.RbacV1().RoleBindings(f.Namespace).Delete(context.Background(), f.rbacName(), metav1.DeleteOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("linkctl floater run error, rolebinding options failed: %v", err)
 		}
 	}
 
 	return nil
 }
 
-func (f *Floater) removeClusterRoleBinding() error {
-	err := f.Client.RbacV1().ClusterRoleBindings().Delete(context.Background(), f.Name, metav1.DeleteOptions{})
+func (f *Floater) removeRole() error {
+	err := f.Client.RbacV1().Roles(f.Namespace).Delete(context.Background(), f.rbacName(), metav1.DeleteOptions{})
 	if err != nil {
 		if !apierrors.IsNotFound(err) {
-			return fmt.Errorf("linkctl floater run error, clusterrolebinding options failed: %v", err)
+			return fmt.Errorf("linkctl floater run error, role options failed: %v", err)
 		}
 	}
 
 	return nil
 }
 
-func (f *Floater) removeClusterRole() error {
-	err := f.Client.RbacV1().ClusterRoles().Delete(context.Background(), f.Name, metav1.DeleteOptions{})
+// LegacyClusterRBACName is the name of the ClusterRole and the ClusterRoleBinding of older versions of linkctl.
+const LegacyClusterRBACName = DefaultFloaterName
+
+// legacyClusterRBAC lists the cluster-wide RBAC objects of older versions of linkctl left in the cluster,
+// nothing is reported when the operator can not read them.
+func (f *Floater) legacyClusterRBAC() []string {
+	var legacy []string
+	if _, err := f.Client.RbacV1().ClusterRoleBindings().Get(context.TODO(), LegacyClusterRBACName, metav1.GetOptions{}); err == nil {
+		legacy = append(legacy, "clusterrolebinding "+LegacyClusterRBACName)
+	}
+	if _, err := f.Client.RbacV1().ClusterRoles().Get(context.TODO(), LegacyClusterRBACName, metav1.GetOptions{}); err == nil {
+		legacy = append(legacy, "clusterrole "+LegacyClusterRBACName)
+	}
+	return legacy
+}
+
+// RemoveLegacyClusterRBAC removes the unscoped clusterlink-floater ClusterRoleBinding and ClusterRole, which
+// older versions of linkctl granted every right with. They belong to no run, only clean --legacy removes them.
+func (f *Floater) RemoveLegacyClusterRBAC() error {
+	klog.Infof("remove the cluster-wide RBAC of older versions: %s", LegacyClusterRBACName)
+	err := f.Client.RbacV1().ClusterRoleBindings().Delete(context.Background(), LegacyClusterRBACName, metav1.DeleteOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("linkctl floater clean error, clusterrolebinding options failed: %v", err)
+		}
+	}
+
+	err = f.Client.RbacV1().ClusterRoles().Delete(context.Background(), LegacyClusterRBACName, metav1.DeleteOptions{})
 	if err != nil {
 		if !apierrors.IsNotFound(err) {
-			return fmt.Errorf("linkctl floater run error, clusterrole options failed: %v", err)
+			return fmt.Errorf("linkctl floater clean error, clusterrole options failed: %v", err)
 		}
 	}
 
@@ -405,7 +1073,7 @@ func (f *Floater) removeClusterRole() error {
 }
 
 func (f *Floater) removeServiceAccount() error {
-	err := f.Client.CoreV1().ServiceAccounts(f.Namespace).Delete(context.Background(), f.Name, metav1.DeleteOptions{})
+	err := f.Client.CoreV1().ServiceAccounts(f.Namespace).Delete(context.Background(), f.rbacName(), metav1.DeleteOptions{})
 	if err != nil {
 		if !apierrors.IsNotFound(err) {
 			return fmt.Errorf("linkctl floater run error, serviceaccount options failed: %v", err)
@@ -0,0 +1,115 @@
+package floater
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+
+	"github.com/kosmos.io/linkctl/pkg/linkctl/manifest"
+	"github.com/kosmos.io/linkctl/pkg/linkctl/util"
+)
+
+func TestDaemonSetDrift(t *testing.T) {
+	base := manifest.DaemonSetReplace{
+		Namespace:          "kosmos-system",
+		Name:               "clusterlink-floater-1a2b3c4d",
+		ImageRepository:    "ghcr.io/kosmos-io",
+		Version:            "v0.2.0",
+		Port:               "8889",
+		ServiceAccountName: "clusterlink-floater-1a2b3c4d",
+		RunID:              "1a2b3c4d",
+		Owner:              "root@host",
+		ExcludeLabel:       ExcludeLabel,
+	}
+	render := func(t *testing.T, change func(*manifest.DaemonSetReplace)) *appsv1.DaemonSet {
+		replace := base
+		if change != nil {
+			change(&replace)
+		}
+		ds, err := util.GenerateDaemonSet(manifest.ClusterlinkFloaterDaemonSet, replace)
+		if err != nil {
+			t.Fatalf("generate daemonset failed: %v", err)
+		}
+		return ds
+	}
+
+	tests := []struct {
+		name     string
+		existing func(*manifest.DaemonSetReplace)
+		// defaulted mimics the fields the API server sets on the existing DaemonSet
+		defaulted func(*appsv1.DaemonSet)
+		want      []string
+	}{
+		{
+			name: "unchanged",
+		},
+		{
+			name: "server defaults",
+			defaulted: func(ds *appsv1.DaemonSet) {
+				ds.Spec.RevisionHistoryLimit = new(int32)
+				container := &ds.Spec.Template.Spec.Containers[0]
+				container.TerminationMessagePath = "/dev/termination-log"
+				container.Env[len(container.Env)-1].ValueFrom.FieldRef.APIVersion = "v1"
+				ds.Spec.Template.Spec.DNSPolicy = "ClusterFirst"
+			},
+		},
+		{
+			name:     "version",
+			existing: func(r *manifest.DaemonSetReplace) { r.Version = "v0.1.0" },
+			want:     []string{"image ghcr.io/kosmos-io/clusterlink-floater:v0.1.0 -> ghcr.io/kosmos-io/clusterlink-floater:v0.2.0"},
+		},
+		{
+			name:     "port",
+			existing: func(r *manifest.DaemonSetReplace) { r.Port = "8888" },
+			want:     []string{`env PORT "8888" -> "8889"`},
+		},
+		{
+			name:     "host network",
+			existing: func(r *manifest.DaemonSetReplace) { r.EnableHostNetwork = true },
+			want:     []string{"hostNetwork true -> false"},
+		},
+		{
+			name:     "mesh",
+			existing: func(r *manifest.DaemonSetReplace) { r.MeshConfigMap = "clusterlink-floater-mesh-1a2b3c4d" },
+			want:     []string{"automountServiceAccountToken changed", `env MESH_CONFIGMAP "clusterlink-floater-mesh-1a2b3c4d" -> ""`},
+		},
+		{
+			name:     "nodes",
+			existing: func(r *manifest.DaemonSetReplace) { r.Nodes = []string{"node-1", "node-2"} },
+			want:     []string{"affinity changed"},
+		},
+		{
+			name: "node selector",
+			existing: func(r *manifest.DaemonSetReplace) {
+				r.NodeSelector = map[string]string{"node-role.kubernetes.io/worker": ""}
+			},
+			want: []string{"nodeSelector changed"},
+		},
+		{
+			name: "older floater without env",
+			defaulted: func(ds *appsv1.DaemonSet) {
+				container := &ds.Spec.Template.Spec.Containers[0]
+				for i, env := range container.Env {
+					if env.Name == "PROBE_API" {
+						container.Env = append(container.Env[:i], container.Env[i+1:]...)
+						break
+					}
+				}
+			},
+			want: []string{`env PROBE_API "" -> "false"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing := render(t, tt.existing)
+			if tt.defaulted != nil {
+				tt.defaulted(existing)
+			}
+			if got := daemonSetDrift(existing, render(t, nil)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("daemonSetDrift() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -39,7 +39,7 @@ func NewCmdInit() *cobra.Command {
 }
 
 func (o *CommandInitOptions) Run() error {
-	_, opts := NewOptions()
+	_, opts := NewOptions(nil)
 	if err := util.WriteOpt(opts); err != nil {
 		klog.Fatal(err)
 	} else {
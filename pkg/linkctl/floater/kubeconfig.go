@@ -0,0 +1,93 @@
+package floater
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/homedir"
+)
+
+// newClientConfig loads a cluster through the standard loading rules, kubeConfigPath and contextName are
+// optional and fall back to --kubeconfig, KUBECONFIG or ~/.kube/config and to the current context.
+// The impersonation flags of configFlags apply to every cluster, the other connection flags only to the
+// default cluster, which is the source cluster.
+func newClientConfig(configFlags *genericclioptions.ConfigFlags, kubeConfigPath, contextName string, defaultCluster bool) clientcmd.ClientConfig {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	overrides := newConfigOverrides(configFlags, defaultCluster)
+
+	if configFlags != nil {
+		loadingRules.ExplicitPath = stringFlag(configFlags.KubeConfig)
+	}
+	if len(kubeConfigPath) > 0 {
+		loadingRules.ExplicitPath = kubeConfigPath
+	}
+	if len(contextName) > 0 {
+		overrides.CurrentContext = contextName
+	}
+
+	// exec credential plugins may prompt the operator
+	return clientcmd.NewInteractiveDeferredLoadingClientConfig(loadingRules, overrides, os.Stdin)
+}
+
+// newKubeConfigClientConfig loads the cluster of the kubeconfig data, like the spec.kubeconfig of a Kosmos
+// cluster object, with the same overrides as the clusters of newClientConfig that are not the default one.
+func newKubeConfigClientConfig(configFlags *genericclioptions.ConfigFlags, data []byte) (clientcmd.ClientConfig, error) {
+	config, err := clientcmd.Load(data)
+	if err != nil {
+		return nil, err
+	}
+	return clientcmd.NewInteractiveClientConfig(*config, "", newConfigOverrides(configFlags, false), os.Stdin, nil), nil
+}
+
+// expandHome replaces the leading ~ of path with the home directory, the shell only expands it at the start
+// of a word and leaves the later paths of a list like --kubeconfig-list untouched.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	return filepath.Join(homedir.HomeDir(), path[1:])
+}
+
+func newConfigOverrides(configFlags *genericclioptions.ConfigFlags, defaultCluster bool) *clientcmd.ConfigOverrides {
+	overrides := &clientcmd.ConfigOverrides{}
+	if configFlags == nil {
+		return overrides
+	}
+
+	overrides.AuthInfo.Impersonate = stringFlag(configFlags.Impersonate)
+	overrides.AuthInfo.ImpersonateUID = stringFlag(configFlags.ImpersonateUID)
+	if configFlags.ImpersonateGroup != nil {
+		overrides.AuthInfo.ImpersonateGroups = *configFlags.ImpersonateGroup
+	}
+	overrides.Timeout = stringFlag(configFlags.Timeout)
+
+	if defaultCluster {
+		overrides.CurrentContext = stringFlag(configFlags.Context)
+		overrides.Context.Cluster = stringFlag(configFlags.ClusterName)
+		overrides.Context.AuthInfo = stringFlag(configFlags.AuthInfoName)
+
+		overrides.ClusterInfo.Server = stringFlag(configFlags.APIServer)
+		overrides.ClusterInfo.TLSServerName = stringFlag(configFlags.TLSServerName)
+		overrides.ClusterInfo.CertificateAuthority = stringFlag(configFlags.CAFile)
+		if configFlags.Insecure != nil {
+			overrides.ClusterInfo.InsecureSkipTLSVerify = *configFlags.Insecure
+		}
+
+		overrides.AuthInfo.ClientCertificate = stringFlag(configFlags.CertFile)
+		overrides.AuthInfo.ClientKey = stringFlag(configFlags.KeyFile)
+		overrides.AuthInfo.Token = stringFlag(configFlags.BearerToken)
+		overrides.AuthInfo.Username = stringFlag(configFlags.Username)
+		overrides.AuthInfo.Password = stringFlag(configFlags.Password)
+	}
+	return overrides
+}
+
+func stringFlag(p *string) string {
+	if p == nil {
+		return ""
+	}
+	return *p
+}
@@ -0,0 +1,123 @@
+package floater
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest"
+)
+
+const memberKubeConfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: cluster-85
+  cluster:
+    server: https://10.0.0.85:6443
+contexts:
+- name: cluster-85
+  context:
+    cluster: cluster-85
+    user: admin
+current-context: cluster-85
+users:
+- name: admin
+  user:
+    token: secret
+`
+
+// TestKubeConfigClientConfig checks that the clusters of --all-clusters get the impersonation of the operator
+// but not the connection flags of the source cluster.
+func TestKubeConfigClientConfig(t *testing.T) {
+	configFlags := genericclioptions.NewConfigFlags(true)
+	*configFlags.Impersonate = "tenant-a"
+	*configFlags.APIServer = "https://10.0.0.84:6443"
+	*configFlags.BearerToken = "operator"
+
+	clientConfig, err := newKubeConfigClientConfig(configFlags, []byte(memberKubeConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := clientConfig.ClientConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Impersonate.UserName != "tenant-a" {
+		t.Errorf("impersonated user = %q, want tenant-a", config.Impersonate.UserName)
+	}
+	if config.Host != "https://10.0.0.85:6443" || config.BearerToken != "secret" {
+		t.Errorf("host %q and token %q, want the server and the token of the member kubeconfig", config.Host, config.BearerToken)
+	}
+
+	if _, err = newKubeConfigClientConfig(configFlags, []byte("clusters: [")); err == nil {
+		t.Error("newKubeConfigClientConfig() accepted an invalid kubeconfig")
+	}
+}
+
+func TestNewClientConfig(t *testing.T) {
+	kubeConfig := filepath.Join(t.TempDir(), "config")
+	data := strings.Replace(memberKubeConfig, "contexts:\n", `contexts:
+- name: cluster-84
+  context:
+    cluster: cluster-84
+    user: admin
+`, 1)
+	data = strings.Replace(data, "clusters:\n", `clusters:
+- name: cluster-84
+  cluster:
+    server: https://10.0.0.84:6443
+`, 1)
+	if err := os.WriteFile(kubeConfig, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	configFlags := genericclioptions.NewConfigFlags(true)
+	*configFlags.KubeConfig = kubeConfig
+	*configFlags.Impersonate = "tenant-a"
+	*configFlags.TLSServerName = "apiserver.cluster-84"
+
+	host := func(contextName string, defaultCluster bool) *rest.Config {
+		t.Helper()
+		config, err := newClientConfig(configFlags, "", contextName, defaultCluster).ClientConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if config.Impersonate.UserName != "tenant-a" {
+			t.Errorf("context %q impersonates %q, want tenant-a", contextName, config.Impersonate.UserName)
+		}
+		return config
+	}
+
+	// the current context of --kubeconfig is the source cluster
+	if config := host("", true); config.Host != "https://10.0.0.85:6443" || config.ServerName != "apiserver.cluster-84" {
+		t.Errorf("source cluster %s with server name %q, want the current context and the connection flags", config.Host, config.ServerName)
+	}
+	if config := host("cluster-84", true); config.Host != "https://10.0.0.84:6443" {
+		t.Errorf("--src-context cluster-84 loaded %s", config.Host)
+	}
+	// the connection flags of the source cluster do not apply to the destination cluster
+	if config := host("cluster-85", false); config.Host != "https://10.0.0.85:6443" || len(config.ServerName) > 0 {
+		t.Errorf("--dst-context cluster-85 loaded %s with server name %q", config.Host, config.ServerName)
+	}
+}
+
+func TestExpandHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	for path, want := range map[string]string{
+		"~/kubeconfig/b":     filepath.Join(home, "kubeconfig/b"),
+		"~":                  home,
+		"/kube-config/b":     "/kube-config/b",
+		"kubeconfig/~/b":     "kubeconfig/~/b",
+		"~tenant/kubeconfig": "~tenant/kubeconfig",
+		"cluster-85.yaml":    "cluster-85.yaml",
+	} {
+		if got := expandHome(path); got != want {
+			t.Errorf("expandHome(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
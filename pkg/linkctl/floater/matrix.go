@@ -0,0 +1,220 @@
+package floater
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/kosmos.io/linkctl/pkg/linkctl/floater/command"
+)
+
+const (
+	ViewList   = "list"
+	ViewMatrix = "matrix"
+	// ViewCluster prints the source cluster x destination cluster grid of a multi-cluster check only
+	ViewCluster = "cluster"
+)
+
+type matrixCell struct {
+	Succeeded int
+	Failed    int
+	Exception int
+	// Warning of the reachable targets, if any
+	Warning string
+}
+
+func (c *matrixCell) Total() int {
+	return c.Succeeded + c.Failed + c.Exception
+}
+
+// String shows the status of a single target, or how many of the targets passed when the destination
+// node has several IPs.
+func (c *matrixCell) String() string {
+	if c.Total() > 1 {
+		return fmt.Sprintf("%d/%d", c.Succeeded, c.Total())
+	}
+	switch {
+	case c.Failed > 0:
+		return "FAIL"
+	case c.Exception > 0:
+		return "ERR"
+	case len(c.Warning) > 0:
+		return c.Warning
+	default:
+		return "OK"
+	}
+}
+
+func (c *matrixCell) add(r *PrintCheckData, warning func(*PrintCheckData) string) {
+	switch r.Status {
+	case command.CommandSuccessed:
+		c.Succeeded++
+		if w := warning(r); len(w) > 0 {
+			c.Warning = w
+		}
+	case command.CommandFailed:
+		c.Failed++
+	default:
+		c.Exception++
+	}
+}
+
+func (c *matrixCell) Color() int {
+	switch {
+	case c.Failed > 0:
+		return tablewriter.FgHiRedColor
+	case c.Exception > 0:
+		return tablewriter.FgCyanColor
+	case len(c.Warning) > 0:
+		return tablewriter.FgYellowColor
+	default:
+		return tablewriter.FgGreenColor
+	}
+}
+
+type connectivityMatrix struct {
+	SrcNodeNames []string
+	DstNodeNames []string
+	Cells        map[string]map[string]*matrixCell
+}
+
+func newConnectivityMatrix(resultData []*PrintCheckData, warning func(*PrintCheckData) string) *connectivityMatrix {
+	m := &connectivityMatrix{
+		Cells: map[string]map[string]*matrixCell{},
+	}
+	dstNodeNames := map[string]struct{}{}
+	for _, r := range resultData {
+		row, ok := m.Cells[r.SrcNodeName]
+		if !ok {
+			row = map[string]*matrixCell{}
+			m.Cells[r.SrcNodeName] = row
+			m.SrcNodeNames = append(m.SrcNodeNames, r.SrcNodeName)
+		}
+		cell, ok := row[r.DstNodeName]
+		if !ok {
+			cell = &matrixCell{}
+			row[r.DstNodeName] = cell
+		}
+		if _, ok = dstNodeNames[r.DstNodeName]; !ok {
+			dstNodeNames[r.DstNodeName] = struct{}{}
+			m.DstNodeNames = append(m.DstNodeNames, r.DstNodeName)
+		}
+
+		cell.add(r, warning)
+	}
+	sort.Strings(m.SrcNodeNames)
+	sort.Strings(m.DstNodeNames)
+
+	return m
+}
+
+// printNodeMatrices renders one grid per cluster pair and mode, the nodes of different modes are not comparable.
+func printNodeMatrices(resultData []*PrintCheckData, warning func(*PrintCheckData) string) {
+	titles, groups := splitResults(resultData, matrixTitle)
+	for _, title := range titles {
+		if len(titles) > 1 {
+			fmt.Println("")
+			fmt.Println(title)
+		}
+		printMatrix(groups[title], warning)
+	}
+}
+
+func matrixTitle(r *PrintCheckData) string {
+	if len(r.SrcCluster) > 0 {
+		return fmt.Sprintf("CLUSTER: %s -> %s, MODE: %s", r.SrcCluster, r.DstCluster, r.Mode)
+	}
+	return fmt.Sprintf("MODE: %s", r.Mode)
+}
+
+// printClusterMatrix renders a source cluster x destination cluster grid, a cell counts the passing checks
+// of all nodes of the pair, --view matrix drills down into the nodes.
+func printClusterMatrix(resultData []*PrintCheckData, warning func(*PrintCheckData) string) {
+	var clusters []string
+	cells := map[string]map[string]*matrixCell{}
+	for _, r := range resultData {
+		for _, cluster := range []string{r.SrcCluster, r.DstCluster} {
+			if !containsString(clusters, cluster) {
+				clusters = append(clusters, cluster)
+			}
+		}
+		row, ok := cells[r.SrcCluster]
+		if !ok {
+			row = map[string]*matrixCell{}
+			cells[r.SrcCluster] = row
+		}
+		cell, ok := row[r.DstCluster]
+		if !ok {
+			cell = &matrixCell{}
+			row[r.DstCluster] = cell
+		}
+		cell.add(r, warning)
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(append([]string{"SRC_CLUSTER \\ DST"}, clusters...))
+	table.SetAutoFormatHeaders(false)
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+	for _, src := range clusters {
+		row := []string{src}
+		colors := []tablewriter.Colors{{}}
+		for _, dst := range clusters {
+			cell, ok := cells[src][dst]
+			if !ok {
+				row = append(row, "-")
+				colors = append(colors, tablewriter.Colors{})
+				continue
+			}
+			row = append(row, fmt.Sprintf("%d/%d", cell.Succeeded, cell.Total()))
+			colors = append(colors, tablewriter.Colors{tablewriter.Bold, cell.Color()})
+		}
+		table.Rich(row, colors)
+	}
+	fmt.Println("")
+	fmt.Println("CLUSTERS")
+	table.Render()
+}
+
+// printMatrix renders a source node x destination node grid, the destination columns are numbered
+// to keep the grid narrow on large clusters and the numbers are explained below the grid.
+func printMatrix(resultData []*PrintCheckData, warning func(*PrintCheckData) string) {
+	m := newConnectivityMatrix(resultData, warning)
+
+	header := []string{"SRC_NODE_NAME \\ DST"}
+	for i := range m.DstNodeNames {
+		header = append(header, strconv.Itoa(i+1))
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(header)
+	table.SetAutoFormatHeaders(false)
+	table.SetAlignment(tablewriter.ALIGN_CENTER)
+
+	for _, src := range m.SrcNodeNames {
+		row := []string{src}
+		colors := []tablewriter.Colors{{}}
+		for _, dst := range m.DstNodeNames {
+			cell, ok := m.Cells[src][dst]
+			if !ok {
+				row = append(row, "-")
+				colors = append(colors, tablewriter.Colors{})
+				continue
+			}
+			row = append(row, cell.String())
+			colors = append(colors, tablewriter.Colors{tablewriter.Bold, cell.Color()})
+		}
+		table.Rich(row, colors)
+	}
+	fmt.Println("")
+	table.Render()
+
+	legend := tablewriter.NewWriter(os.Stdout)
+	legend.SetHeader([]string{"DST", "DST_NODE_NAME"})
+	for i, dst := range m.DstNodeNames {
+		legend.Append([]string{strconv.Itoa(i + 1), dst})
+	}
+	fmt.Println("")
+	legend.Render()
+}
@@ -0,0 +1,116 @@
+package floater
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/klog/v2"
+	ctlutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/kosmos.io/linkctl/pkg/mesh"
+)
+
+var meshExample = templates.Examples(i18n.T(`
+        # Let the floaters ping each other every minute, e.g:
+        linkctl mesh --src-kubeconfig ~/kubeconfig/src-kubeconfig
+
+        # Probe the floater port over TCP every 30 seconds, e.g:
+        linkctl mesh --src-kubeconfig ~/kubeconfig/src-kubeconfig --protocol tcp --period 30
+`))
+
+const (
+	DefaultMeshName = DefaultFloaterName + "-mesh"
+)
+
+type CommandMeshOptions struct {
+	*CommandCheckOptions
+
+	Period int
+}
+
+func NewCmdMesh(configFlags *genericclioptions.ConfigFlags) *cobra.Command {
+	cmd, checkOpt := NewOptions(configFlags)
+
+	o := &CommandMeshOptions{CommandCheckOptions: checkOpt}
+	cmd.Use = "mesh"
+	cmd.Short = i18n.T("Let the floaters probe each other continuously, read the results with linkctl status")
+	cmd.Example = meshExample
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		ctlutil.CheckErr(o.Complete())
+		ctlutil.CheckErr(o.Validate())
+		ctlutil.CheckErr(o.Run())
+		return nil
+	}
+	cmd.Flags().IntVar(&o.Period, "period", mesh.DefaultPeriod, "Seconds between two rounds of probes of every floater.")
+	return cmd
+}
+
+func (o *CommandMeshOptions) Validate() error {
+	if o.HasDst() {
+		return fmt.Errorf("mesh only covers the source cluster, dst-kubeconfig and dst-context are not supported")
+	}
+	if o.MultiCluster() {
+		return fmt.Errorf("mesh only covers the cluster of src-kubeconfig, kubeconfig-list, contexts and all-clusters are not supported")
+	}
+	if len(o.Mode) > 0 {
+		return fmt.Errorf("mode is not supported by mesh, the floaters probe each other in the network of --host-network")
+	}
+	if o.Period < 1 {
+		return fmt.Errorf("period must be at least 1 second")
+	}
+	return o.CommandCheckOptions.Validate()
+}
+
+func (o *CommandMeshOptions) Run() error {
+	if err := o.Preflight(true); err != nil {
+		return err
+	}
+
+	o.SrcFloater.MeshConfigMap = MeshName(o.RunID)
+	if err := o.SrcFloater.CreateFloater(); err != nil {
+		return err
+	}
+
+	var floatInfos []*FloatInfo
+	var err error
+	if o.SrcFloater.EnableHostNetwork {
+		floatInfos, err = o.SrcFloater.GetNodesInfo()
+	} else {
+		floatInfos, err = o.SrcFloater.GetPodInfo()
+	}
+	if err != nil {
+		return fmt.Errorf("get src cluster floaters failed: %s", err)
+	}
+	floatInfos = o.SrcFloater.SelectInfos(floatInfos)
+
+	req := NewProbeRequest(Protocol(o.Protocol), "", o.probeOptions())
+	config := &mesh.Config{
+		Period:     o.Period,
+		Protocol:   req.Protocol,
+		Port:       req.Port,
+		Timeout:    req.Timeout,
+		Count:      req.Count,
+		Interval:   req.Interval,
+		PacketSize: req.PacketSize,
+	}
+	for _, info := range floatInfos {
+		peer := mesh.Peer{NodeName: info.NodeName, IPs: info.PodIPs}
+		if o.SrcFloater.EnableHostNetwork {
+			peer.IPs = info.NodeIPs
+		}
+		config.Peers = append(config.Peers, peer)
+	}
+
+	if err = o.SrcFloater.ApplyMeshConfig(config); err != nil {
+		return err
+	}
+	klog.Infof("mesh %s probes %d floaters every %ds, run linkctl status to read the results", o.SrcFloater.MeshConfigMap, len(config.Peers), o.Period)
+
+	// save options for clean
+	o.SaveOpts()
+
+	return nil
+}
@@ -0,0 +1,246 @@
+package floater
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+
+	"github.com/kosmos.io/linkctl/pkg/linkctl/util"
+)
+
+// MemberCluster is one of the clusters of a multi-cluster check.
+type MemberCluster struct {
+	Name    string
+	Floater *Floater
+}
+
+// MultiCluster reports whether every ordered pair of a list of clusters is checked instead of src and dst.
+func (o *CommandCheckOptions) MultiCluster() bool {
+	return len(o.KubeConfigList) > 0 || len(o.Contexts) > 0 || o.AllClusters
+}
+
+// completeMembers builds a floater per cluster of --kubeconfig-list, --contexts or --all-clusters, the floater
+// of the first cluster also serves as the source floater.
+func (o *CommandCheckOptions) completeMembers() error {
+	o.Members = nil
+	if o.AllClusters {
+		if err := o.completeClusterMembers(); err != nil {
+			return err
+		}
+	} else if len(o.Contexts) > 0 {
+		for _, contextName := range o.Contexts {
+			floater := NewCheckFloater(o, false)
+			if err := floater.completeFromClientConfig(newClientConfig(o.ConfigFlags, "", contextName, false)); err != nil {
+				return err
+			}
+			o.Members = append(o.Members, &MemberCluster{Name: contextName, Floater: floater})
+		}
+	} else {
+		for i, kubeConfig := range o.KubeConfigList {
+			o.KubeConfigList[i] = expandHome(kubeConfig)
+		}
+		names := kubeConfigClusterNames(o.KubeConfigList)
+		for i, kubeConfig := range o.KubeConfigList {
+			floater := NewCheckFloater(o, false)
+			if err := floater.completeFromClientConfig(newClientConfig(o.ConfigFlags, kubeConfig, "", false)); err != nil {
+				return err
+			}
+			o.Members = append(o.Members, &MemberCluster{Name: names[i], Floater: floater})
+		}
+	}
+	if !o.AllClusters {
+		o.completeMemberCIDRsMaps()
+	}
+
+	if len(o.Members) == 0 {
+		return fmt.Errorf("no cluster to check")
+	}
+	o.SrcFloater = o.Members[0].Floater
+	return nil
+}
+
+// completeClusterMembers builds a floater per Kosmos cluster object found in the control cluster of
+// --src-kubeconfig and --src-context, clusters whose object can not be used are skipped.
+func (o *CommandCheckOptions) completeClusterMembers() error {
+	control := &Floater{}
+	if err := control.completeFromClientConfig(newClientConfig(o.ConfigFlags, o.SrcKubeConfig, o.SrcContext, true)); err != nil {
+		return err
+	}
+	clusters, err := control.DynamicClient.Resource(util.ClusterGVR).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("list kosmos clusters failed: %v", err)
+	}
+
+	for i := range clusters.Items {
+		cluster := &clusters.Items[i]
+		member, err := o.newClusterMember(cluster)
+		if err != nil {
+			klog.Warningf("skip cluster %s: %v", cluster.GetName(), err)
+			continue
+		}
+		klog.Infof("found cluster %s, namespace: %s, image repository: %s", member.Name, member.Floater.Namespace, member.Floater.ImageRepository)
+		o.Members = append(o.Members, member)
+	}
+	return nil
+}
+
+// completeMemberCIDRsMaps maps the target IPs of the members of --kubeconfig-list and --contexts with the
+// globalCIDRsMap of the Kosmos cluster object named like the member, the object is looked up in every member
+// cluster since it lives in the control cluster. Members without object are checked without global CIDRs.
+func (o *CommandCheckOptions) completeMemberCIDRsMaps() {
+	for _, member := range o.Members {
+		for _, lookup := range o.Members {
+			cidrsMap, err := lookup.Floater.GetClusterCIDRsMap(member.Name)
+			if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
+				continue
+			}
+			if err != nil {
+				klog.Warningf("get globalCIDRsMap of cluster %s failed: %v", member.Name, err)
+				break
+			}
+			if len(cidrsMap) > 0 {
+				klog.Infof("map the IPs of cluster %s with globalCIDRsMap: %v", member.Name, cidrsMap)
+			}
+			member.Floater.CIDRsMap = cidrsMap
+			break
+		}
+	}
+}
+
+// newClusterMember builds the floater of a Kosmos cluster object from its base64 encoded kubeconfig, its
+// namespace, image repository and globalCIDRsMap take precedence over the flags.
+func (o *CommandCheckOptions) newClusterMember(cluster *unstructured.Unstructured) (*MemberCluster, error) {
+	kubeConfig, _, err := unstructured.NestedString(cluster.Object, "spec", "kubeconfig")
+	if err != nil {
+		return nil, err
+	}
+	if len(kubeConfig) == 0 {
+		return nil, fmt.Errorf("spec.kubeconfig is empty")
+	}
+	data, err := base64.StdEncoding.DecodeString(kubeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("decode spec.kubeconfig failed: %v", err)
+	}
+	clientConfig, err := newKubeConfigClientConfig(o.ConfigFlags, data)
+	if err != nil {
+		return nil, fmt.Errorf("load spec.kubeconfig failed: %v", err)
+	}
+
+	floater := NewCheckFloater(o, false)
+	if namespace, _, _ := unstructured.NestedString(cluster.Object, "spec", "namespace"); len(namespace) > 0 {
+		floater.Namespace = namespace
+	}
+	if imageRepository, _, _ := unstructured.NestedString(cluster.Object, "spec", "imageRepository"); len(imageRepository) > 0 {
+		floater.ImageRepository = imageRepository
+	}
+	if floater.CIDRsMap, err = clusterCIDRsMap(cluster); err != nil {
+		return nil, err
+	}
+	if err = floater.completeFromClientConfig(clientConfig); err != nil {
+		return nil, err
+	}
+
+	return &MemberCluster{Name: cluster.GetName(), Floater: floater}, nil
+}
+
+// kubeConfigClusterNames names the clusters after their kubeconfig files without extension, the paths
+// are kept for files sharing a name.
+func kubeConfigClusterNames(kubeConfigs []string) []string {
+	names := make([]string, 0, len(kubeConfigs))
+	count := map[string]int{}
+	for _, kubeConfig := range kubeConfigs {
+		base := filepath.Base(kubeConfig)
+		name := strings.TrimSuffix(base, filepath.Ext(base))
+		names = append(names, name)
+		count[name]++
+	}
+	for i, name := range names {
+		if count[name] > 1 {
+			names[i] = kubeConfigs[i]
+		}
+	}
+	return names
+}
+
+// RunMultiCluster starts the floaters in every member cluster and checks every ordered pair of clusters,
+// the target IPs are mapped with the globalCIDRsMap of the destination cluster, a cluster paired with
+// itself is checked without global CIDRs.
+func (o *CommandCheckOptions) RunMultiCluster() ([]*PrintCheckData, error) {
+	modes := o.checkModes()
+	var networks []bool
+	for _, mode := range modes {
+		srcHost, dstHost := mode.hostNetworks()
+		networks = append(networks, srcHost, dstHost)
+	}
+
+	infos := make([]map[bool][]*FloatInfo, 0, len(o.Members))
+	for _, member := range o.Members {
+		memberInfos, err := prepareFloaters(member.Floater, member.Name, networks)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, memberInfos)
+	}
+
+	var resultData []*PrintCheckData
+	for i, src := range o.Members {
+		for j, dst := range o.Members {
+			klog.Infof("check cluster %s -> %s", src.Name, dst.Name)
+			pair := *o
+			pair.SrcFloater, pair.DstFloater = src.Floater, dst.Floater
+			pair.scope.SrcCluster, pair.scope.DstCluster = src.Name, dst.Name
+			if i == j {
+				pair.DstFloater = nil
+			}
+			for _, mode := range modes {
+				srcHost, dstHost := mode.hostNetworks()
+				resultData = append(resultData, pair.runMode(mode, infos[i][srcHost], infos[j][dstHost])...)
+			}
+		}
+	}
+
+	return resultData, nil
+}
+
+// validateMultiCluster rejects the options of a src and dst check.
+func (o *CommandCheckOptions) validateMultiCluster() error {
+	sources := 0
+	for _, set := range []bool{len(o.KubeConfigList) > 0, len(o.Contexts) > 0, o.AllClusters} {
+		if set {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return fmt.Errorf("only one of kubeconfig-list, contexts and all-clusters can be used")
+	}
+	if !o.MultiCluster() {
+		if o.View == ViewCluster {
+			return fmt.Errorf("view %s requires kubeconfig-list, contexts or all-clusters", ViewCluster)
+		}
+		return nil
+	}
+
+	if len(o.Members) < 2 {
+		return fmt.Errorf("a multi-cluster check needs at least two clusters, got %d", len(o.Members))
+	}
+	if o.HasDst() {
+		return fmt.Errorf("dst-kubeconfig and dst-context can not be used with kubeconfig-list, contexts or all-clusters")
+	}
+	if o.Bidirectional {
+		return fmt.Errorf("every ordered pair of clusters is checked already, bidirectional is not needed")
+	}
+	if o.Services {
+		return fmt.Errorf("services can only be checked between src-kubeconfig and dst-kubeconfig")
+	}
+	if len(o.DstClusterName) > 0 || len(o.CIDRsMap) > 0 {
+		return fmt.Errorf("dst-cluster-name and cidrs-map can only be used with dst-kubeconfig")
+	}
+	return nil
+}
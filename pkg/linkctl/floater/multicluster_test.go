@@ -0,0 +1,53 @@
+package floater
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestKubeConfigClusterNames(t *testing.T) {
+	check := func(kubeConfigs []string, want ...string) {
+		t.Helper()
+		if want == nil {
+			want = []string{}
+		}
+		if got := kubeConfigClusterNames(kubeConfigs); !reflect.DeepEqual(got, want) {
+			t.Errorf("kubeConfigClusterNames(%q) = %q, want %q", kubeConfigs, got, want)
+		}
+	}
+
+	check([]string{"/kube-config/cluster-84", "/kube-config/cluster-85"}, "cluster-84", "cluster-85")
+	// only the last extension is dropped
+	check([]string{"/root/.kube/cluster-84.yaml", "cluster-85.config.yml"}, "cluster-84", "cluster-85.config")
+	// files sharing a name keep their paths, the others are still named after the file
+	check([]string{"/kube-config/84/config", "/kube-config/85/config", "/kube-config/cluster-86"},
+		"/kube-config/84/config", "/kube-config/85/config", "cluster-86")
+	check([]string{"/a/member.yaml", "/b/member.yml"}, "/a/member.yaml", "/b/member.yml")
+	check(nil)
+}
+
+func TestValidateMultiCluster(t *testing.T) {
+	members := []*MemberCluster{{Name: "cluster-84"}, {Name: "cluster-85"}}
+
+	o := &CommandCheckOptions{Contexts: []string{"cluster-84", "cluster-85"}, Members: members}
+	if err := o.validateMultiCluster(); err != nil {
+		t.Fatalf("validateMultiCluster() error = %v", err)
+	}
+
+	rejected := map[string]*CommandCheckOptions{
+		"only one of":       {Contexts: []string{"cluster-84"}, AllClusters: true, Members: members},
+		"at least two":      {KubeConfigList: []string{"/kube-config/cluster-84"}, Members: members[:1]},
+		"dst-kubeconfig":    {Contexts: []string{"cluster-84", "cluster-85"}, DstContext: "cluster-86", Members: members},
+		"bidirectional":     {Contexts: []string{"cluster-84", "cluster-85"}, Bidirectional: true, Members: members},
+		"cidrs-map":         {AllClusters: true, CIDRsMap: map[string]string{"10.222.0.0/16": "210.222.0.0/16"}, Members: members},
+		"requires":          {View: ViewCluster},
+		"services can only": {AllClusters: true, Services: true, Members: members},
+	}
+	for want, o := range rejected {
+		err := o.validateMultiCluster()
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("validateMultiCluster() error = %v, want %q", err, want)
+		}
+	}
+}
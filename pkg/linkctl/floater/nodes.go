@@ -0,0 +1,230 @@
+package floater
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/kosmos.io/linkctl/pkg/linkctl/util"
+)
+
+const (
+	// ExcludeLabel keeps the floaters off a node
+	ExcludeLabel = "kosmos.io/exclude"
+	// ZoneLabel groups the nodes of --sample-per-zone
+	ZoneLabel = "topology.kubernetes.io/zone"
+)
+
+// NodeSelection picks the nodes of a cluster the floaters run on, and thereby the pairs probed between them.
+// It is shared by the pod and the host network floaters of the cluster so that both sample the same nodes.
+type NodeSelection struct {
+	NodeSelector  map[string]string
+	Nodes         []string
+	ExcludeNodes  []string
+	Sample        int
+	SamplePerZone int
+
+	// mu guards sampled and resolved, the floaters of both networks and the checks of several cluster pairs
+	// share the selection
+	mu sync.Mutex
+	// sampled holds the nodes chosen by sampling once they are resolved
+	sampled  []string
+	resolved bool
+}
+
+// nodeSelection returns the selection of the flags, every cluster gets its own since the nodes differ.
+func (o *CommandCheckOptions) nodeSelection() *NodeSelection {
+	return &NodeSelection{
+		NodeSelector:  o.NodeSelector,
+		Nodes:         o.Nodes,
+		ExcludeNodes:  o.ExcludeNodes,
+		Sample:        o.Sample,
+		SamplePerZone: o.SamplePerZone,
+	}
+}
+
+func (s *NodeSelection) sampling() bool {
+	return s != nil && (s.Sample > 0 || s.SamplePerZone > 0)
+}
+
+// resolveNodes samples the nodes the floaters are scheduled on, among the nodes matching the selector and
+// the node lists. Namespaced floaters can not list the Nodes, they run on all selected nodes and the
+// pairs are sampled among their pods instead.
+func (f *Floater) resolveNodes() error {
+	s := f.Selection
+	if s == nil || !s.sampling() || f.Namespaced {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.resolved {
+		return nil
+	}
+
+	nodes, err := f.Client.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{
+		LabelSelector: util.MapToString(s.NodeSelector),
+	})
+	if err != nil {
+		return fmt.Errorf("linkctl floater run error, list nodes to sample failed: %v", err)
+	}
+
+	var candidates []corev1.Node
+	for _, node := range nodes.Items {
+		if _, ok := node.Labels[ExcludeLabel]; ok {
+			continue
+		}
+		if s.allowed(node.Name) {
+			candidates = append(candidates, node)
+		}
+	}
+	if len(candidates) == 0 {
+		return fmt.Errorf("linkctl floater run error, no node matches the node selection")
+	}
+
+	if s.SamplePerZone > 0 {
+		s.sampled = sampleNodesPerZone(candidates, s.SamplePerZone)
+	} else {
+		names := make([]string, 0, len(candidates))
+		for _, node := range candidates {
+			names = append(names, node.Name)
+		}
+		s.sampled = sampleNames(names, s.Sample)
+	}
+	s.resolved = true
+	klog.Infof("run the floaters on %d of %d nodes: %s", len(s.sampled), len(candidates), strings.Join(s.sampled, ", "))
+	return nil
+}
+
+// allowed reports whether a node passes --nodes and --exclude-nodes.
+func (s *NodeSelection) allowed(name string) bool {
+	if len(s.Nodes) > 0 && !containsString(s.Nodes, name) {
+		return false
+	}
+	return !containsString(s.ExcludeNodes, name)
+}
+
+// scheduledNodes returns the nodes the DaemonSet is pinned to, none pins it to every node.
+func (s *NodeSelection) scheduledNodes() []string {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.resolved {
+		return s.sampled
+	}
+	var nodes []string
+	for _, name := range s.Nodes {
+		if s.allowed(name) {
+			nodes = append(nodes, name)
+		}
+	}
+	return nodes
+}
+
+// excludedNodes returns the nodes kept free of floaters when the DaemonSet is not pinned to given nodes.
+func (s *NodeSelection) excludedNodes() []string {
+	if s == nil || len(s.scheduledNodes()) > 0 {
+		return nil
+	}
+	return s.ExcludeNodes
+}
+
+func (s *NodeSelection) nodeSelector() map[string]string {
+	if s == nil {
+		return nil
+	}
+	return s.NodeSelector
+}
+
+// SelectInfos keeps the floaters running on the selected nodes, floaters of a DaemonSet that was scheduled
+// on more nodes by an earlier run are skipped. The floaters of a namespaced check are sampled here.
+func (f *Floater) SelectInfos(infos []*FloatInfo) []*FloatInfo {
+	s := f.Selection
+	if s == nil {
+		return infos
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.sampling() && !s.resolved {
+		var names []string
+		for _, info := range infos {
+			if s.allowed(info.NodeName) && !containsString(names, info.NodeName) {
+				names = append(names, info.NodeName)
+			}
+		}
+		// sample-per-zone is rejected with namespaced, the zones are only known from the Node objects
+		s.sampled = sampleNames(names, s.Sample)
+		s.resolved = true
+		klog.Infof("probe between the floaters of %d of %d nodes: %s", len(s.sampled), len(names), strings.Join(s.sampled, ", "))
+	}
+
+	var selected []*FloatInfo
+	for _, info := range infos {
+		if s.resolved {
+			if containsString(s.sampled, info.NodeName) {
+				selected = append(selected, info)
+			}
+			continue
+		}
+		if s.allowed(info.NodeName) {
+			selected = append(selected, info)
+		}
+	}
+	return selected
+}
+
+// selectPairs keeps the source and the destination floaters running on the selected nodes of their cluster.
+func (o *CommandCheckOptions) selectPairs(iInfos, jInfos []*FloatInfo) ([]*FloatInfo, []*FloatInfo) {
+	dst := o.DstFloater
+	if dst == nil {
+		dst = o.SrcFloater
+	}
+	return o.SrcFloater.SelectInfos(iInfos), dst.SelectInfos(jInfos)
+}
+
+var (
+	// sampleRand is not safe for concurrent use, sampleMutex guards it
+	sampleRand  = rand.New(rand.NewSource(time.Now().UnixNano()))
+	sampleMutex sync.Mutex
+)
+
+// sampleNames picks n of names at random, the result is sorted.
+func sampleNames(names []string, n int) []string {
+	sampled := append([]string(nil), names...)
+	if n < len(sampled) {
+		sampleMutex.Lock()
+		sampleRand.Shuffle(len(sampled), func(i, j int) {
+			sampled[i], sampled[j] = sampled[j], sampled[i]
+		})
+		sampleMutex.Unlock()
+		sampled = sampled[:n]
+	}
+	sort.Strings(sampled)
+	return sampled
+}
+
+// sampleNodesPerZone picks k nodes of every zone, nodes without zone label form a zone of their own.
+func sampleNodesPerZone(nodes []corev1.Node, k int) []string {
+	zones := map[string][]string{}
+	for _, node := range nodes {
+		zone := node.Labels[ZoneLabel]
+		zones[zone] = append(zones[zone], node.Name)
+	}
+
+	var sampled []string
+	for _, names := range zones {
+		sampled = append(sampled, sampleNames(names, k)...)
+	}
+	sort.Strings(sampled)
+	return sampled
+}
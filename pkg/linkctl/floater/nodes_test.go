@@ -0,0 +1,139 @@
+package floater
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSampleNames(t *testing.T) {
+	names := []string{"node-3", "node-1", "node-5", "node-2", "node-4"}
+
+	tests := []struct {
+		name string
+		n    int
+		want int
+	}{
+		{name: "some", n: 2, want: 2},
+		{name: "one", n: 1, want: 1},
+		{name: "all", n: 5, want: 5},
+		{name: "more than all", n: 8, want: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the sample is random, every run has to satisfy the same properties
+			for i := 0; i < 20; i++ {
+				got := sampleNames(names, tt.n)
+				if len(got) != tt.want {
+					t.Fatalf("sampleNames() = %v, want %d names", got, tt.want)
+				}
+				if !sort.StringsAreSorted(got) {
+					t.Errorf("sampleNames() = %v is not sorted", got)
+				}
+				seen := map[string]bool{}
+				for _, name := range got {
+					if !containsString(names, name) || seen[name] {
+						t.Errorf("sampleNames() = %v is not a subset of %v", got, names)
+					}
+					seen[name] = true
+				}
+			}
+		})
+	}
+
+	if names[0] != "node-3" {
+		t.Errorf("sampleNames() reordered its input: %v", names)
+	}
+}
+
+func TestSampleNodesPerZone(t *testing.T) {
+	node := func(name, zone string) corev1.Node {
+		n := corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if len(zone) > 0 {
+			n.Labels = map[string]string{ZoneLabel: zone}
+		}
+		return n
+	}
+	var nodes []corev1.Node
+	zoneOf := map[string]string{}
+	for zone, count := range map[string]int{"zone-a": 4, "zone-b": 1, "zone-c": 3, "": 2} {
+		for i := 0; i < count; i++ {
+			name := fmt.Sprintf("node-%s-%d", zone, i)
+			nodes = append(nodes, node(name, zone))
+			zoneOf[name] = zone
+		}
+	}
+
+	tests := []struct {
+		name string
+		k    int
+		want map[string]int
+	}{
+		{name: "one per zone", k: 1, want: map[string]int{"zone-a": 1, "zone-b": 1, "zone-c": 1, "": 1}},
+		{name: "two per zone", k: 2, want: map[string]int{"zone-a": 2, "zone-b": 1, "zone-c": 2, "": 2}},
+		{name: "all", k: 5, want: map[string]int{"zone-a": 4, "zone-b": 1, "zone-c": 3, "": 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				got := sampleNodesPerZone(nodes, tt.k)
+				if !sort.StringsAreSorted(got) {
+					t.Errorf("sampleNodesPerZone() = %v is not sorted", got)
+				}
+				perZone := map[string]int{}
+				for _, name := range got {
+					zone, ok := zoneOf[name]
+					if !ok {
+						t.Fatalf("sampleNodesPerZone() returned unknown node %s", name)
+					}
+					perZone[zone]++
+				}
+				for zone, want := range tt.want {
+					if perZone[zone] != want {
+						t.Errorf("sampleNodesPerZone() = %v, picked %d nodes of zone %q, want %d", got, perZone[zone], zone, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestSelectInfosShared samples a namespaced selection from the floaters of both networks at once, they
+// must all probe between the same nodes.
+func TestSelectInfosShared(t *testing.T) {
+	var infos []*FloatInfo
+	for i := 0; i < 10; i++ {
+		infos = append(infos, &FloatInfo{NodeName: fmt.Sprintf("node-%d", i)})
+	}
+	selection := &NodeSelection{Sample: 3}
+	pod := &Floater{Selection: selection, Namespaced: true}
+	host := pod.WithHostNetwork(true)
+
+	selected := make([][]*FloatInfo, 8)
+	var wg sync.WaitGroup
+	for i := range selected {
+		f := pod
+		if i%2 == 1 {
+			f = host
+		}
+		wg.Add(1)
+		go func(i int, f *Floater) {
+			defer wg.Done()
+			selected[i] = f.SelectInfos(infos)
+		}(i, f)
+	}
+	wg.Wait()
+
+	for _, infos := range selected {
+		if len(infos) != 3 || !reflect.DeepEqual(infos, selected[0]) {
+			t.Fatalf("SelectInfos() = %v and %v, want the same 3 floaters", infos, selected[0])
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package floater
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/kosmos.io/linkctl/pkg/linkctl/util"
+	"github.com/kosmos.io/linkctl/pkg/utils"
+)
+
+// permission is an API access of the operator, namespaced permissions are reviewed in Namespace, which
+// defaults to the namespace of the floaters. Name restricts it to a single object.
+type permission struct {
+	Verb        string
+	Group       string
+	Resource    string
+	Subresource string
+	Name        string
+	Namespaced  bool
+	Namespace   string
+}
+
+func (p permission) String() string {
+	resource := p.Resource
+	if len(p.Group) > 0 {
+		resource += "." + p.Group
+	}
+	if len(p.Subresource) > 0 {
+		resource += "/" + p.Subresource
+	}
+	if len(p.Name) > 0 {
+		resource += " " + p.Name
+	}
+	return p.Verb + " " + resource
+}
+
+func permissions(group, resource string, namespaced bool, verbs ...string) []permission {
+	perms := make([]permission, 0, len(verbs))
+	for _, verb := range verbs {
+		perms = append(perms, permission{Verb: verb, Group: group, Resource: resource, Namespaced: namespaced})
+	}
+	return perms
+}
+
+// requiredPermissions lists what the operator needs to deploy the floaters, probe through them and remove them,
+// mesh adds the Role of the agents and extra the reads of the command.
+func (o *CommandCheckOptions) requiredPermissions(mesh bool, extra ...permission) []permission {
+	var perms []permission
+	if !o.Namespaced {
+		perms = append(perms, permissions("", "namespaces", false, "get", "create")...)
+	}
+	perms = append(perms, permissions("", "serviceaccounts", true, "get", "create", "update")...)
+	// the floaters of the other runs are listed to keep clear of their host port and namespace
+	perms = append(perms, permissions("apps", "daemonsets", true, "get", "list", "create", "update")...)
+	perms = append(perms, permissions("", "pods", true, "get", "list")...)
+
+	if (o.usesHostNetwork() || o.Sample > 0 || o.SamplePerZone > 0) && !o.Namespaced {
+		perms = append(perms, permissions("", "nodes", false, "list")...)
+	}
+	if o.ProbeMode == ProbeModeAPI {
+		perms = append(perms, permission{Verb: "get", Resource: "pods", Subresource: "proxy", Namespaced: true})
+	} else {
+		perms = append(perms, permission{Verb: "create", Resource: "pods", Subresource: "exec", Namespaced: true})
+	}
+
+	if mesh {
+		perms = append(perms, permissions("rbac.authorization.k8s.io", "roles", true, "get", "create", "update")...)
+		perms = append(perms, permissions("rbac.authorization.k8s.io", "rolebindings", true, "get", "create", "update")...)
+		// a Role can only grant what its creator holds, the agents read the mesh config that linkctl writes,
+		// the update of their status ConfigMaps is granted once the peers are known
+		perms = append(perms, permissions("", "configmaps", true, "create")...)
+		for _, verb := range []string{"get", "update"} {
+			perms = append(perms, permission{Verb: verb, Resource: "configmaps", Name: MeshName(o.RunID), Namespaced: true})
+		}
+	}
+	if o.Services {
+		perms = append(perms, permissions("", "services", true, "get", "create", "delete")...)
+		perms = append(perms, permissions(util.ServiceExportGVR.Group, util.ServiceExportGVR.Resource, true, "create", "delete")...)
+		perms = append(perms, permissions(util.ServiceImportGVR.Group, util.ServiceImportGVR.Resource, true, "get", "create", "delete")...)
+	}
+	if o.AutoClean {
+		perms = append(perms, permissions("apps", "daemonsets", true, "list", "delete")...)
+		perms = append(perms, permissions("", "serviceaccounts", true, "delete")...)
+		perms = append(perms, permissions("", "configmaps", true, "deletecollection")...)
+		perms = append(perms, permissions("rbac.authorization.k8s.io", "roles", true, "delete")...)
+		perms = append(perms, permissions("rbac.authorization.k8s.io", "rolebindings", true, "delete")...)
+		// the Service of an interrupted check --services is removed as well
+		perms = append(perms, permissions("", "services", true, "delete")...)
+		perms = append(perms, permissions(util.ServiceExportGVR.Group, util.ServiceExportGVR.Resource, true, "delete")...)
+		perms = append(perms, permissions(util.ServiceImportGVR.Group, util.ServiceImportGVR.Resource, true, "delete")...)
+		if o.Namespace != utils.DefaultNamespace && !o.Namespaced {
+			perms = append(perms, permissions("", "namespaces", false, "delete")...)
+		}
+	}
+	perms = append(perms, extra...)
+
+	unique := perms[:0]
+	seen := map[permission]bool{}
+	for _, p := range perms {
+		if !seen[p] {
+			seen[p] = true
+			unique = append(unique, p)
+		}
+	}
+	return unique
+}
+
+// usesHostNetwork reports whether any floater of the check runs in the host network.
+func (o *CommandCheckOptions) usesHostNetwork() bool {
+	for _, mode := range o.checkModes() {
+		if srcHost, dstHost := mode.hostNetworks(); srcHost || dstHost {
+			return true
+		}
+	}
+	return false
+}
+
+// MissingPermissions asks the API server which of perms the user of the kubeconfig of f lacks.
+func (f *Floater) MissingPermissions(perms []permission) ([]permission, error) {
+	var missing []permission
+	for _, p := range perms {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Verb:        p.Verb,
+					Group:       p.Group,
+					Resource:    p.Resource,
+					Subresource: p.Subresource,
+					Name:        p.Name,
+				},
+			},
+		}
+		if p.Namespaced {
+			review.Spec.ResourceAttributes.Namespace = f.permissionNamespace(p)
+		}
+		review, err := f.Client.AuthorizationV1().SelfSubjectAccessReviews().Create(context.TODO(), review, metav1.CreateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("review permission %s failed: %v", p, err)
+		}
+		if !review.Status.Allowed {
+			missing = append(missing, p)
+		}
+	}
+	return missing, nil
+}
+
+func (f *Floater) permissionNamespace(p permission) string {
+	if len(p.Namespace) > 0 {
+		return p.Namespace
+	}
+	return f.Namespace
+}
+
+// Preflight reviews the permissions of the operator in every cluster before anything is deployed, it fails
+// with the list of the missing ones.
+func (o *CommandCheckOptions) Preflight(mesh bool, extra ...permission) error {
+	type cluster struct {
+		name    string
+		floater *Floater
+	}
+	var clusters []cluster
+	if o.MultiCluster() {
+		for _, member := range o.Members {
+			clusters = append(clusters, cluster{member.Name, member.Floater})
+		}
+	} else {
+		clusters = append(clusters, cluster{"src", o.SrcFloater})
+		if o.DstFloater != nil {
+			clusters = append(clusters, cluster{"dst", o.DstFloater})
+		}
+	}
+
+	perms := o.requiredPermissions(mesh, extra...)
+	var lines []string
+	for _, c := range clusters {
+		if !o.Namespaced {
+			if legacy := c.floater.legacyClusterRBAC(); len(legacy) > 0 {
+				klog.Warningf("cluster %s: %s of an older linkctl grant every right to its floaters, remove them with linkctl clean --legacy", c.name, strings.Join(legacy, " and "))
+			}
+		}
+		missing, err := c.floater.MissingPermissions(perms)
+		if err != nil {
+			return fmt.Errorf("linkctl preflight error, cluster %s: %v", c.name, err)
+		}
+		for _, p := range missing {
+			scope := "cluster-wide"
+			if p.Namespaced {
+				scope = "in namespace " + c.floater.permissionNamespace(p)
+			}
+			lines = append(lines, fmt.Sprintf("  cluster %s: %s %s", c.name, p, scope))
+		}
+	}
+	if len(lines) > 0 {
+		return fmt.Errorf("linkctl preflight error, the kubeconfig is missing %d permissions:\n%s", len(lines), strings.Join(lines, "\n"))
+	}
+
+	klog.Infof("preflight passed, %d permissions reviewed in %d clusters", len(perms), len(clusters))
+	return nil
+}
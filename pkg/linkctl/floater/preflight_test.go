@@ -0,0 +1,120 @@
+package floater
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kosmos.io/linkctl/pkg/linkctl/manifest"
+	"github.com/kosmos.io/linkctl/pkg/linkctl/util"
+	"github.com/kosmos.io/linkctl/pkg/utils"
+)
+
+// permissionSet indexes perms by their String and reports duplicates.
+func permissionSet(t *testing.T, perms []permission) map[string]permission {
+	t.Helper()
+	set := map[string]permission{}
+	for _, p := range perms {
+		if _, ok := set[p.String()]; ok && p.Namespace == set[p.String()].Namespace {
+			t.Errorf("permission %s is listed twice", p)
+		}
+		set[p.String()] = p
+	}
+	return set
+}
+
+func TestRequiredPermissions(t *testing.T) {
+	o := &CommandCheckOptions{Namespace: "linkctl-check", ProbeMode: ProbeModeExec}
+	perms := permissionSet(t, o.requiredPermissions(false))
+	for _, want := range []string{"create namespaces", "create daemonsets.apps", "list daemonsets.apps", "create pods/exec"} {
+		if _, ok := perms[want]; !ok {
+			t.Errorf("check is missing %s", want)
+		}
+	}
+	for _, unwanted := range []string{"get pods/proxy", "list nodes", "create roles.rbac.authorization.k8s.io", "delete namespaces"} {
+		if _, ok := perms[unwanted]; ok {
+			t.Errorf("check requires %s", unwanted)
+		}
+	}
+	if !perms["create pods/exec"].Namespaced || perms["create namespaces"].Namespaced {
+		t.Error("pods/exec must be reviewed in the namespace of the floaters, namespaces cluster-wide")
+	}
+
+	// the probe API replaces the exec sessions, the host network floaters need the Nodes
+	o = &CommandCheckOptions{Namespace: "linkctl-check", ProbeMode: ProbeModeAPI, HostNetwork: true}
+	perms = permissionSet(t, o.requiredPermissions(true))
+	for _, want := range []string{"get pods/proxy", "list nodes", "create roles.rbac.authorization.k8s.io", "create configmaps"} {
+		if _, ok := perms[want]; !ok {
+			t.Errorf("api mesh is missing %s", want)
+		}
+	}
+	if _, ok := perms["create pods/exec"]; ok {
+		t.Error("api mode requires pods/exec")
+	}
+	// only the config of the mesh is read and updated by name
+	for _, unwanted := range []string{"get configmaps", "list configmaps", "update configmaps"} {
+		if _, ok := perms[unwanted]; ok {
+			t.Errorf("api mesh requires %s in the whole namespace", unwanted)
+		}
+	}
+	for _, want := range []string{"get configmaps " + MeshName(o.RunID), "update configmaps " + MeshName(o.RunID)} {
+		if _, ok := perms[want]; !ok {
+			t.Errorf("api mesh is missing %s", want)
+		}
+	}
+
+	// a namespaced check reads no cluster-wide object, even when it removes its floaters
+	o = &CommandCheckOptions{Namespace: "team-a", ProbeMode: ProbeModeExec, Namespaced: true, HostNetwork: true, AutoClean: true}
+	for _, p := range o.requiredPermissions(false) {
+		if !p.Namespaced {
+			t.Errorf("namespaced check requires %s cluster-wide", p)
+		}
+	}
+
+	// the default namespace is kept by clean
+	o = &CommandCheckOptions{Namespace: utils.DefaultNamespace, ProbeMode: ProbeModeExec, AutoClean: true}
+	if _, ok := permissionSet(t, o.requiredPermissions(false))["delete namespaces"]; ok {
+		t.Errorf("clean of %s requires delete namespaces", utils.DefaultNamespace)
+	}
+}
+
+// TestMeshRole checks that the agents may create ConfigMaps but only read the config of their mesh and update
+// the status of its peers.
+func TestMeshRole(t *testing.T) {
+	replace := manifest.RoleReplace{
+		Namespace: "linkctl-check",
+		Name:      "clusterlink-floater-1c474ad5",
+		RunID:     "1c474ad5",
+		ConfigMap: MeshName("1c474ad5"),
+	}
+	rules := func() map[string][]string {
+		t.Helper()
+		role, err := util.GenerateRole(manifest.ClusterlinkFloaterRole, replace)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := map[string][]string{}
+		for _, rule := range role.Rules {
+			if !reflect.DeepEqual(rule.Resources, []string{"configmaps"}) {
+				t.Errorf("role grants %v", rule.Resources)
+			}
+			for _, verb := range rule.Verbs {
+				if _, ok := names[verb]; ok {
+					t.Errorf("role grants %s twice", verb)
+				}
+				names[verb] = rule.ResourceNames
+			}
+		}
+		return names
+	}
+
+	want := map[string][]string{"create": nil, "get": {MeshName("1c474ad5")}}
+	if got := rules(); !reflect.DeepEqual(got, want) {
+		t.Errorf("role before the peers are known grants %v, want %v", got, want)
+	}
+
+	replace.StatusConfigMaps = []string{MeshName("1c474ad5") + "-node-1", MeshName("1c474ad5") + "-node-2"}
+	want["update"] = replace.StatusConfigMaps
+	if got := rules(); !reflect.DeepEqual(got, want) {
+		t.Errorf("role grants %v, want %v", got, want)
+	}
+}
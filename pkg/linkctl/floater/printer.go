@@ -0,0 +1,626 @@
+package floater
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	uexec "k8s.io/utils/exec"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kosmos.io/linkctl/pkg/linkctl/floater/command"
+	"github.com/kosmos.io/linkctl/pkg/linkctl/util"
+)
+
+const (
+	OutputTable = ""
+	OutputWide  = "wide"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+	OutputCSV   = "csv"
+	OutputJUnit = "junit"
+)
+
+var SupportedOutputs = []string{OutputWide, OutputJSON, OutputYAML, OutputCSV, OutputJUnit}
+
+func IsSupportedOutput(output string) bool {
+	if output == OutputTable {
+		return true
+	}
+	for _, o := range SupportedOutputs {
+		if o == output {
+			return true
+		}
+	}
+	return false
+}
+
+// IsStructuredOutput reports whether the results are printed for machines, nothing else may be written to stdout.
+func IsStructuredOutput(output string) bool {
+	return output != OutputTable && output != OutputWide
+}
+
+const (
+	// ExitCodeFailed more targets than tolerated are not reachable
+	ExitCodeFailed = 2
+	// ExitCodeException some probes could not be executed
+	ExitCodeException = 3
+)
+
+type CheckSummary struct {
+	Total     int `json:"total"`
+	Succeeded int `json:"succeeded"`
+	Warning   int `json:"warning"`
+	Failed    int `json:"failed"`
+	Exception int `json:"exception"`
+}
+
+func NewCheckSummary(resultData []*PrintCheckData, warning func(*PrintCheckData) string) CheckSummary {
+	summary := CheckSummary{Total: len(resultData)}
+	for _, r := range resultData {
+		switch r.Status {
+		case command.CommandSuccessed:
+			summary.Succeeded++
+			if len(warning(r)) > 0 {
+				summary.Warning++
+			}
+		case command.CommandFailed:
+			summary.Failed++
+		case command.ExecError:
+			summary.Exception++
+		}
+	}
+	return summary
+}
+
+func (s CheckSummary) FailedPercent() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return float64(s.Failed) * 100 / float64(s.Total)
+}
+
+// ExitError maps the summary to the exit code of linkctl, failed targets above failThreshold percent take
+// precedence over exceptions.
+func (s CheckSummary) ExitError(failThreshold float64) error {
+	if s.Failed > 0 && s.FailedPercent() > failThreshold {
+		return uexec.CodeExitError{
+			Err:  fmt.Errorf("%d of %d checks failed (%.2f%%, threshold %.2f%%)", s.Failed, s.Total, s.FailedPercent(), failThreshold),
+			Code: ExitCodeFailed,
+		}
+	}
+	if s.Exception > 0 {
+		return uexec.CodeExitError{
+			Err:  fmt.Errorf("%d of %d checks hit an exception", s.Exception, s.Total),
+			Code: ExitCodeException,
+		}
+	}
+	return nil
+}
+
+type CheckRecord struct {
+	SrcNodeName string                  `json:"srcNodeName"`
+	DstNodeName string                  `json:"dstNodeName"`
+	TargetIP    string                  `json:"targetIP"`
+	SrcCluster  string                  `json:"srcCluster,omitempty"`
+	DstCluster  string                  `json:"dstCluster,omitempty"`
+	Mode        string                  `json:"mode,omitempty"`
+	Direction   string                  `json:"direction,omitempty"`
+	Status      string                  `json:"status"`
+	Warning     string                  `json:"warning,omitempty"`
+	LatencyMs   float64                 `json:"latencyMs"`
+	Statistics  *command.PingStatistics `json:"statistics,omitempty"`
+	MTU         *command.MTUStatistics  `json:"mtu,omitempty"`
+	Log         string                  `json:"log,omitempty"`
+}
+
+type CheckReport struct {
+	Summary    CheckSummary     `json:"summary"`
+	Results    []CheckRecord    `json:"results"`
+	Asymmetric []AsymmetricPath `json:"asymmetric,omitempty"`
+}
+
+func NewCheckReport(resultData []*PrintCheckData, warning func(*PrintCheckData) string) *CheckReport {
+	report := &CheckReport{
+		Summary:    NewCheckSummary(resultData, warning),
+		Results:    make([]CheckRecord, 0, len(resultData)),
+		Asymmetric: FindAsymmetricPaths(resultData),
+	}
+	for _, r := range resultData {
+		report.Results = append(report.Results, CheckRecord{
+			SrcNodeName: r.SrcNodeName,
+			DstNodeName: r.DstNodeName,
+			TargetIP:    r.TargetIP,
+			SrcCluster:  r.SrcCluster,
+			DstCluster:  r.DstCluster,
+			Mode:        r.Mode,
+			Direction:   r.Direction,
+			Status:      command.PrintStatus(r.Status),
+			Warning:     warning(r),
+			LatencyMs:   durationToMs(r.Elapsed),
+			Statistics:  r.Statistics,
+			MTU:         r.MTU,
+			Log:         r.ResultStr,
+		})
+	}
+	return report
+}
+
+func durationToMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// formatMTU returns the path and the interface MTU columns of a result.
+func formatMTU(mtu *command.MTUStatistics) []string {
+	if mtu == nil {
+		return []string{"-", "-"}
+	}
+	return []string{strconv.Itoa(mtu.PathMTU), strconv.Itoa(mtu.InterfaceMTU)}
+}
+
+// formatStatistics returns the min, avg, max, mdev and loss columns of a result.
+func formatStatistics(stats *command.PingStatistics) []string {
+	if stats == nil {
+		return []string{"-", "-", "-", "-", "-"}
+	}
+	loss := strconv.FormatFloat(stats.Loss, 'f', -1, 64) + "%"
+	if stats.Received == 0 {
+		return []string{"-", "-", "-", "-", loss}
+	}
+	ms := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', 3, 64) + "ms"
+	}
+	return []string{ms(stats.Min), ms(stats.Avg), ms(stats.Max), ms(stats.Mdev), loss}
+}
+
+func (o *CommandCheckOptions) PrintResult(resultData []*PrintCheckData) {
+	o.printResultData(resultData)
+
+	resumeData := []*PrintCheckData{}
+	for _, r := range resultData {
+		if r.Status == command.CommandFailed || r.Status == command.ExecError {
+			resumeData = append(resumeData, r)
+		}
+	}
+	util.WriteResume(resumeData)
+}
+
+// printResultData renders resultData in the output format and view of o.
+func (o *CommandCheckOptions) printResultData(resultData []*PrintCheckData) {
+	var err error
+	switch o.Output {
+	case OutputJSON:
+		err = printJSON(os.Stdout, NewCheckReport(resultData, o.Warning))
+	case OutputYAML:
+		err = printYAML(os.Stdout, NewCheckReport(resultData, o.Warning))
+	case OutputCSV:
+		err = printCSV(os.Stdout, NewCheckReport(resultData, o.Warning))
+	case OutputJUnit:
+		err = printJUnit(os.Stdout, NewCheckReport(resultData, o.Warning))
+	default:
+		if o.MultiCluster() {
+			printClusterMatrix(resultData, o.Warning)
+		}
+		switch o.View {
+		case ViewCluster:
+			// the cluster matrix only
+		case ViewMatrix:
+			printNodeMatrices(resultData, o.Warning)
+		default:
+			if o.Output == OutputWide {
+				printWideTable(resultData, o.Warning)
+			} else {
+				printTable(resultData, o.Warning)
+			}
+		}
+		printAsymmetricPaths(FindAsymmetricPaths(resultData))
+		printFailureGroups(resultData)
+		printSummary(NewCheckSummary(resultData, o.Warning))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "print result error: %s\n", err)
+	}
+}
+
+var statusColors = map[int]int{
+	command.CommandSuccessed: tablewriter.FgGreenColor,
+	command.CommandFailed:    tablewriter.FgHiRedColor,
+	command.ExecError:        tablewriter.FgCyanColor,
+}
+
+func rowColors(color int, columns int) []tablewriter.Colors {
+	colors := []tablewriter.Colors{{}}
+	for i := 1; i < columns; i++ {
+		colors = append(colors, tablewriter.Colors{tablewriter.Bold, color})
+	}
+	return colors
+}
+
+// resultColumn shows the warning instead of the status of a reachable target.
+func resultColumn(r *PrintCheckData, warning func(*PrintCheckData) string) (string, int) {
+	if w := warning(r); len(w) > 0 {
+		return w, tablewriter.FgYellowColor
+	}
+	return command.PrintStatus(r.Status), statusColors[r.Status]
+}
+
+// splitResults groups the results by key, the keys are returned in the order they were checked.
+func splitResults(resultData []*PrintCheckData, key func(*PrintCheckData) string) ([]string, map[string][]*PrintCheckData) {
+	var keys []string
+	groups := map[string][]*PrintCheckData{}
+	for _, r := range resultData {
+		k := key(r)
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], r)
+	}
+	return keys, groups
+}
+
+func resultMode(r *PrintCheckData) string {
+	return r.Mode
+}
+
+// srcNodeName and dstNodeName qualify the node names with their cluster when several clusters are checked.
+func srcNodeName(r *PrintCheckData) string {
+	if len(r.SrcCluster) > 0 {
+		return r.SrcCluster + "/" + r.SrcNodeName
+	}
+	return r.SrcNodeName
+}
+
+func dstNodeName(r *PrintCheckData) string {
+	if len(r.DstCluster) > 0 {
+		return r.DstCluster + "/" + r.DstNodeName
+	}
+	return r.DstNodeName
+}
+
+// pairLayout tells which of the optional columns naming a checked pair are printed, the mode, the
+// direction and the clusters are only shown when the results span several of them.
+type pairLayout struct {
+	Mode      bool
+	Direction bool
+	Cluster   bool
+}
+
+func newPairLayout(resultData []*PrintCheckData) pairLayout {
+	modes, _ := splitResults(resultData, resultMode)
+	layout := pairLayout{Mode: len(modes) > 1}
+	for _, r := range resultData {
+		if len(r.Direction) > 0 {
+			layout.Direction = true
+		}
+		if len(r.SrcCluster) > 0 {
+			layout.Cluster = true
+		}
+	}
+	return layout
+}
+
+func (l pairLayout) header() []string {
+	var header []string
+	if l.Direction {
+		header = append(header, "DIRECTION")
+	}
+	if l.Mode {
+		header = append(header, "MODE")
+	}
+	if l.Cluster {
+		header = append(header, "SRC_CLUSTER", "DST_CLUSTER")
+	}
+	return append(header, "SRC_NODE_NAME", "DST_NODE_NAME", "TARGET_IP")
+}
+
+func (l pairLayout) columns(r *PrintCheckData) []string {
+	var columns []string
+	if l.Direction {
+		columns = append(columns, r.Direction)
+	}
+	if l.Mode {
+		columns = append(columns, r.Mode)
+	}
+	if l.Cluster {
+		columns = append(columns, r.SrcCluster, r.DstCluster)
+	}
+	return append(columns, r.SrcNodeName, r.DstNodeName, r.TargetIP)
+}
+
+// printTable renders one table per status, the successful checks are printed with their latency or
+// their path MTU instead of their log.
+func printTable(resultData []*PrintCheckData, warning func(*PrintCheckData) string) {
+	layout := newPairLayout(resultData)
+	withMTU := false
+	for _, r := range resultData {
+		if r.MTU != nil {
+			withMTU = true
+			break
+		}
+	}
+
+	for _, status := range []int{command.CommandSuccessed, command.CommandFailed, command.ExecError} {
+		header := append(append([]string{"S/N"}, layout.header()...), "RESULT")
+		withLog := status != command.CommandSuccessed
+		if withLog {
+			header = append(header, "LOG")
+		} else if withMTU {
+			header = append(header, "PATH_MTU", "INTERFACE_MTU")
+		} else {
+			header = append(header, "RTT_AVG", "LOSS")
+		}
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader(header)
+
+		rows := 0
+		for index, r := range resultData {
+			if r.Status != status {
+				continue
+			}
+			rows++
+			result, color := resultColumn(r, warning)
+			row := append(append([]string{strconv.Itoa(index + 1)}, layout.columns(r)...), result)
+			colored := len(row)
+			if withLog {
+				row = append(row, r.ResultStr)
+			} else if withMTU {
+				row = append(row, formatMTU(r.MTU)...)
+			} else {
+				stats := formatStatistics(r.Statistics)
+				row = append(row, stats[1], stats[4])
+			}
+			table.Rich(row, rowColors(color, colored))
+		}
+		if rows == 0 {
+			continue
+		}
+		fmt.Println("")
+		fmt.Printf("%s: %d\n", command.PrintStatus(status), rows)
+		table.Render()
+	}
+}
+
+type nodeFailures struct {
+	NodeName  string
+	Failed    int
+	Exception int
+	Total     int
+}
+
+// groupFailures counts the failed and exception checks per node, nodes with the most failures come first.
+func groupFailures(resultData []*PrintCheckData, nodeName func(*PrintCheckData) string) []*nodeFailures {
+	groups := map[string]*nodeFailures{}
+	for _, r := range resultData {
+		name := nodeName(r)
+		g, ok := groups[name]
+		if !ok {
+			g = &nodeFailures{NodeName: name}
+			groups[name] = g
+		}
+		g.Total++
+		switch r.Status {
+		case command.CommandFailed:
+			g.Failed++
+		case command.ExecError:
+			g.Exception++
+		}
+	}
+
+	var ret []*nodeFailures
+	for _, g := range groups {
+		if g.Failed+g.Exception > 0 {
+			ret = append(ret, g)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Failed != ret[j].Failed {
+			return ret[i].Failed > ret[j].Failed
+		}
+		if ret[i].Exception != ret[j].Exception {
+			return ret[i].Exception > ret[j].Exception
+		}
+		return ret[i].NodeName < ret[j].NodeName
+	})
+	return ret
+}
+
+// printFailureGroups shows the failures by source and by destination node, a node failing all of its
+// checks points at the node itself rather than at a single path.
+func printFailureGroups(resultData []*PrintCheckData) {
+	groupBy := []struct {
+		title    string
+		nodeName func(*PrintCheckData) string
+	}{
+		{"FAILURES BY SOURCE NODE", srcNodeName},
+		{"FAILURES BY DESTINATION NODE", dstNodeName},
+	}
+
+	for _, by := range groupBy {
+		groups := groupFailures(resultData, by.nodeName)
+		if len(groups) == 0 {
+			continue
+		}
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"NODE_NAME", "FAILED", "EXCEPTION", "TOTAL"})
+		for _, g := range groups {
+			color := tablewriter.FgCyanColor
+			if g.Failed > 0 {
+				color = tablewriter.FgHiRedColor
+			}
+			table.Rich([]string{g.NodeName, strconv.Itoa(g.Failed), strconv.Itoa(g.Exception), strconv.Itoa(g.Total)}, []tablewriter.Colors{
+				{tablewriter.Bold, color},
+			})
+		}
+		fmt.Println("")
+		fmt.Println(by.title)
+		table.Render()
+	}
+}
+
+func printWideTable(resultData []*PrintCheckData, warning func(*PrintCheckData) string) {
+	layout := newPairLayout(resultData)
+
+	header := append(append([]string{"S/N"}, layout.header()...), "RESULT")
+	header = append(header, "LATENCY", "RTT_MIN", "RTT_AVG", "RTT_MAX", "RTT_MDEV", "LOSS", "PATH_MTU", "INTERFACE_MTU", "LOG")
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(header)
+
+	for index, r := range resultData {
+		result, color := resultColumn(r, warning)
+		row := append(append([]string{strconv.Itoa(index + 1)}, layout.columns(r)...), result)
+		colored := len(row)
+		row = append(row, r.Elapsed.Round(time.Millisecond).String())
+		row = append(row, formatStatistics(r.Statistics)...)
+		row = append(row, formatMTU(r.MTU)...)
+		row = append(row, r.ResultStr)
+		table.Rich(row, rowColors(color, colored))
+	}
+	fmt.Println("")
+	table.Render()
+}
+
+func printSummary(summary CheckSummary) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"TOTAL", "SUCCESSED", "WARNING", "FAILED", "EXCEPTION", "FAILED_RATE"})
+	table.Rich([]string{
+		strconv.Itoa(summary.Total),
+		strconv.Itoa(summary.Succeeded),
+		strconv.Itoa(summary.Warning),
+		strconv.Itoa(summary.Failed),
+		strconv.Itoa(summary.Exception),
+		fmt.Sprintf("%.2f%%", summary.FailedPercent()),
+	}, []tablewriter.Colors{
+		{},
+		{tablewriter.Bold, tablewriter.FgGreenColor},
+		{tablewriter.Bold, tablewriter.FgYellowColor},
+		{tablewriter.Bold, tablewriter.FgHiRedColor},
+		{tablewriter.Bold, tablewriter.FgCyanColor},
+		{},
+	})
+	fmt.Println("")
+	table.Render()
+}
+
+func printJSON(w io.Writer, report *CheckReport) error {
+	b, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(b))
+	return err
+}
+
+func printYAML(w io.Writer, report *CheckReport) error {
+	b, err := yaml.Marshal(report)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+func printCSV(w io.Writer, report *CheckReport) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"src_node_name", "dst_node_name", "target_ip", "status", "warning", "latency_ms",
+		"rtt_min_ms", "rtt_avg_ms", "rtt_max_ms", "rtt_mdev_ms", "packet_loss", "path_mtu", "interface_mtu", "log", "mode", "direction", "src_cluster", "dst_cluster"}); err != nil {
+		return err
+	}
+	for _, r := range report.Results {
+		record := []string{r.SrcNodeName, r.DstNodeName, r.TargetIP, r.Status, r.Warning, strconv.FormatFloat(r.LatencyMs, 'f', 3, 64)}
+		if r.Statistics != nil {
+			for _, v := range []float64{r.Statistics.Min, r.Statistics.Avg, r.Statistics.Max, r.Statistics.Mdev, r.Statistics.Loss} {
+				record = append(record, strconv.FormatFloat(v, 'f', 3, 64))
+			}
+		} else {
+			record = append(record, "", "", "", "", "")
+		}
+		if r.MTU != nil {
+			record = append(record, strconv.Itoa(r.MTU.PathMTU), strconv.Itoa(r.MTU.InterfaceMTU))
+		} else {
+			record = append(record, "", "")
+		}
+		record = append(record, r.Log, r.Mode, r.Direction, r.SrcCluster, r.DstCluster)
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Content string `xml:",chardata"`
+}
+
+func printJUnit(w io.Writer, report *CheckReport) error {
+	suite := junitTestSuite{
+		Name:  "linkctl check",
+		Tests: len(report.Results),
+	}
+	var total float64
+	for _, r := range report.Results {
+		name := fmt.Sprintf("%s -> %s (%s)", r.SrcNodeName, r.DstNodeName, r.TargetIP)
+		if len(r.SrcCluster) > 0 {
+			name = fmt.Sprintf("%s/%s -> %s/%s (%s)", r.SrcCluster, r.SrcNodeName, r.DstCluster, r.DstNodeName, r.TargetIP)
+		}
+		if len(r.Mode) > 0 {
+			name = fmt.Sprintf("%s %s", r.Mode, name)
+		}
+		if len(r.Direction) > 0 {
+			name = fmt.Sprintf("%s %s", r.Direction, name)
+		}
+		testCase := junitTestCase{
+			Name:      name,
+			ClassName: r.SrcNodeName,
+			Time:      strconv.FormatFloat(r.LatencyMs/1000, 'f', 3, 64),
+		}
+		switch r.Status {
+		case command.PrintStatus(command.CommandFailed):
+			suite.Failures++
+			testCase.Failure = &junitMessage{Message: r.Status, Content: r.Log}
+		case command.PrintStatus(command.ExecError):
+			suite.Errors++
+			testCase.Error = &junitMessage{Message: r.Status, Content: r.Log}
+		}
+		total += r.LatencyMs / 1000
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+	suite.Time = strconv.FormatFloat(total, 'f', 3, 64)
+
+	b, err := xml.MarshalIndent(junitTestSuites{Suites: []junitTestSuite{suite}}, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s%s\n", xml.Header, b)
+	return err
+}
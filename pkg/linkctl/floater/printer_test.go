@@ -0,0 +1,161 @@
+package floater
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	uexec "k8s.io/utils/exec"
+
+	"github.com/kosmos.io/linkctl/pkg/linkctl/floater/command"
+)
+
+func reportOf(resultData ...*PrintCheckData) *CheckReport {
+	return NewCheckReport(resultData, func(*PrintCheckData) string { return "" })
+}
+
+func checkData(status int, src, dst, ip string) *PrintCheckData {
+	return &PrintCheckData{
+		Result:      command.Result{Status: status, ResultStr: command.PrintStatus(status) + " log", Elapsed: 1500 * time.Microsecond},
+		SrcNodeName: src,
+		DstNodeName: dst,
+		TargetIP:    ip,
+	}
+}
+
+func TestPrintCSV(t *testing.T) {
+	ping := checkData(command.CommandSuccessed, "node-1", "node-2", "10.233.64.12")
+	ping.Statistics = &command.PingStatistics{Transmitted: 4, Received: 3, Loss: 25, Min: 0.5, Avg: 0.75, Max: 1, Mdev: 0.25}
+	mtu := checkData(command.CommandSuccessed, "node-1", "node-3", "10.233.65.7")
+	mtu.MTU = &command.MTUStatistics{PathMTU: 1400, InterfaceMTU: 1450}
+	// the log of a failed probe spans lines and holds commas
+	failed := checkData(command.CommandFailed, "node-2", "node-1", "10.233.64.9")
+	failed.ResultStr = "ping: sendto: Network unreachable,\nretry"
+
+	var buf bytes.Buffer
+	if err := printCSV(&buf, reportOf(ping, mtu, failed)); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("printCSV() wrote invalid csv: %v", err)
+	}
+	if len(records) != 4 {
+		t.Fatalf("printCSV() wrote %d records, want a header and 3 results", len(records))
+	}
+
+	column := map[string]int{}
+	for i, name := range records[0] {
+		column[name] = i
+	}
+	for _, record := range records[1:] {
+		if len(record) != len(records[0]) {
+			t.Fatalf("header has %d columns, a result %d", len(records[0]), len(record))
+		}
+	}
+	for name, want := range map[string]string{"status": "SUCCESSED", "latency_ms": "1.500", "rtt_avg_ms": "0.750", "packet_loss": "25.000", "path_mtu": ""} {
+		if got := records[1][column[name]]; got != want {
+			t.Errorf("ping %s = %q, want %q", name, got, want)
+		}
+	}
+	if got := records[2][column["path_mtu"]] + "/" + records[2][column["interface_mtu"]]; got != "1400/1450" {
+		t.Errorf("mtu path_mtu/interface_mtu = %q, want 1400/1450", got)
+	}
+	if got := records[3][column["log"]]; got != failed.ResultStr {
+		t.Errorf("failed log = %q, want %q", got, failed.ResultStr)
+	}
+}
+
+func TestPrintJUnit(t *testing.T) {
+	reverse := checkData(command.CommandSuccessed, "node-2", "node-1", "10.233.64.12")
+	reverse.Mode, reverse.Direction = string(ModePodPod), DirectionReverse
+	report := reportOf(
+		checkData(command.CommandSuccessed, "node-1", "node-2", "10.233.64.13"),
+		checkData(command.CommandFailed, "node-1", "node-3", "10.233.65.7"),
+		checkData(command.ExecError, "node-1", "node-4", "10.233.66.2"),
+		reverse,
+	)
+
+	var buf bytes.Buffer
+	if err := printJUnit(&buf, report); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), xml.Header) {
+		t.Errorf("printJUnit() did not write the xml header")
+	}
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+		t.Fatalf("printJUnit() wrote invalid xml: %v", err)
+	}
+	if len(suites.Suites) != 1 {
+		t.Fatalf("printJUnit() wrote %d suites, want 1", len(suites.Suites))
+	}
+	suite := suites.Suites[0]
+	if suite.Tests != 4 || suite.Failures != 1 || suite.Errors != 1 || suite.Time != "0.006" {
+		t.Errorf("suite tests=%d failures=%d errors=%d time=%s, want 4, 1, 1 and 0.006", suite.Tests, suite.Failures, suite.Errors, suite.Time)
+	}
+	if c := suite.TestCases[1]; c.Failure == nil || c.Failure.Content != "FAILED log" || c.Error != nil {
+		t.Errorf("failed case = %+v, want a failure with its log", c)
+	}
+	if c := suite.TestCases[2]; c.Error == nil || c.Failure != nil {
+		t.Errorf("exception case = %+v, want an error", c)
+	}
+	if got, want := suite.TestCases[3].Name, "reverse pod-pod node-2 -> node-1 (10.233.64.12)"; got != want {
+		t.Errorf("reverse case name = %q, want %q", got, want)
+	}
+}
+
+func TestCheckSummaryExitError(t *testing.T) {
+	exitCode := func(err error) int {
+		var exitErr uexec.CodeExitError
+		if err == nil {
+			return 0
+		}
+		if !errors.As(err, &exitErr) {
+			t.Fatalf("ExitError() = %v, want a CodeExitError", err)
+		}
+		return exitErr.Code
+	}
+
+	slow := func(r *PrintCheckData) string {
+		if r.DstNodeName == "node-2" {
+			return "SLOW"
+		}
+		return ""
+	}
+	summary := NewCheckSummary([]*PrintCheckData{
+		checkData(command.CommandSuccessed, "node-1", "node-2", "10.233.64.12"),
+		checkData(command.CommandSuccessed, "node-1", "node-3", "10.233.65.7"),
+		checkData(command.CommandSuccessed, "node-1", "node-4", "10.233.66.2"),
+		checkData(command.CommandFailed, "node-1", "node-5", "10.233.67.4"),
+	}, slow)
+	if summary != (CheckSummary{Total: 4, Succeeded: 3, Warning: 1, Failed: 1}) {
+		t.Errorf("NewCheckSummary() = %+v, want 4 checks with 1 warning and 1 failure", summary)
+	}
+
+	// 25% of the checks failed
+	if code := exitCode(summary.ExitError(0)); code != ExitCodeFailed {
+		t.Errorf("exit code = %d, want %d without threshold", code, ExitCodeFailed)
+	}
+	if code := exitCode(summary.ExitError(25)); code != 0 {
+		t.Errorf("exit code = %d, want 0 at the threshold", code)
+	}
+
+	// exceptions are reported even below the fail threshold, failures above it take precedence
+	summary.Exception, summary.Total = 1, 5
+	if code := exitCode(summary.ExitError(50)); code != ExitCodeException {
+		t.Errorf("exit code = %d, want %d below the fail threshold", code, ExitCodeException)
+	}
+	if code := exitCode(summary.ExitError(10)); code != ExitCodeFailed {
+		t.Errorf("exit code = %d, want %d above the fail threshold", code, ExitCodeFailed)
+	}
+
+	if code := exitCode((CheckSummary{}).ExitError(0)); code != 0 {
+		t.Errorf("exit code = %d, want 0 without checks", code)
+	}
+}
@@ -2,6 +2,7 @@ package floater
 
 import (
 	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
 	ctlutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/util/i18n"
 
@@ -9,14 +10,14 @@ import (
 )
 
 type CommandResumeOptions struct {
-	CommandCheckOptions
+	*CommandCheckOptions
 }
 
-func NewCmdResume() *cobra.Command {
-	cmd, checkOpt := NewOptions()
+func NewCmdResume(configFlags *genericclioptions.ConfigFlags) *cobra.Command {
+	cmd, checkOpt := NewOptions(configFlags)
+	checkOpt.reuseRun = true
 
-	o := &CommandResumeOptions{}
-	o.CommandCheckOptions = *checkOpt
+	o := &CommandResumeOptions{CommandCheckOptions: checkOpt}
 	cmd.Use = "resume"
 	cmd.Short = i18n.T("resume network connectivity between Kosmos clusters")
 	cmd.Example = checkExample
@@ -0,0 +1,180 @@
+package floater
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/user"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/kosmos.io/linkctl/pkg/mesh"
+	"github.com/kosmos.io/linkctl/pkg/utils"
+)
+
+const (
+	// RunLabel is set on the floaters and the RBAC objects of a run, its value is the run ID
+	RunLabel = "kosmos.io/floater-run"
+	// OwnerAnnotation tells who started the run, as user@host
+	OwnerAnnotation = "kosmos.io/floater-owner"
+)
+
+// NewRunID returns a random run ID, it is short enough to suffix the names of the floater objects.
+func NewRunID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		klog.Fatalf("generate run id failed: %v", err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// RunOwner identifies the operator starting a run as user@host.
+func RunOwner() string {
+	name := os.Getenv("USER")
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	if len(name) == 0 {
+		name = "unknown"
+	}
+	host, err := os.Hostname()
+	if err != nil || len(host) == 0 {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s@%s", name, host)
+}
+
+// runScopedName suffixes name with the run ID, objects of floaters without a run keep their plain name.
+func runScopedName(name, runID string) string {
+	if len(runID) == 0 {
+		return name
+	}
+	return name + "-" + runID
+}
+
+// MeshName returns the name of the config ConfigMap of the mesh of a run.
+func MeshName(runID string) string {
+	return runScopedName(DefaultMeshName, runID)
+}
+
+// rbacName is the name of the ServiceAccount, the Role and the RoleBinding of the run.
+func (f *Floater) rbacName() string {
+	return runScopedName(DefaultFloaterName, f.RunID)
+}
+
+// ServiceName is the name of the Service the floaters of the run export.
+func (f *Floater) ServiceName() string {
+	return runScopedName(DefaultFloaterServiceName, f.RunID)
+}
+
+// completeRunID reuses the run of --run-id or of config.json, a new run is started otherwise.
+func (o *CommandCheckOptions) completeRunID() {
+	if len(o.RunID) > 0 {
+		return
+	}
+	o.RunID = NewRunID()
+	o.Owner = RunOwner()
+	o.newRun = true
+	klog.Infof("start run %s, owner: %s", o.RunID, o.Owner)
+}
+
+// ListMeshes returns the names of the meshes running in the namespace of f.
+func (f *Floater) ListMeshes() ([]string, error) {
+	cms, err := f.Client.CoreV1().ConfigMaps(f.Namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: mesh.Label,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, cm := range cms.Items {
+		// the config ConfigMap is labeled with its own name, the status ones with the name of their mesh
+		if cm.Labels[mesh.Label] == cm.Name {
+			names = append(names, cm.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// otherRuns returns the runs other than the one of f whose floaters are left in the namespace.
+func (f *Floater) otherRuns() ([]string, error) {
+	dss, err := f.Client.AppsV1().DaemonSets(f.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return floaterRuns(dss.Items, f.RunID), nil
+}
+
+// floaterRuns lists the runs of the floater DaemonSets other than runID, the floaters of older versions of linkctl
+// carry no run label and are listed by name.
+func floaterRuns(dss []appsv1.DaemonSet, runID string) []string {
+	own := map[string]bool{
+		runScopedName(DefaultFloaterName, runID):     true,
+		runScopedName(DefaultHostFloaterName, runID): true,
+	}
+
+	var runs []string
+	seen := map[string]bool{}
+	for _, ds := range dss {
+		if !strings.HasPrefix(ds.Name, DefaultFloaterName) || own[ds.Name] {
+			continue
+		}
+		run := ds.Labels[RunLabel]
+		if len(run) == 0 {
+			runs = append(runs, fmt.Sprintf("%s (unlabeled)", ds.Name))
+			continue
+		}
+		if run == runID || seen[run] {
+			continue
+		}
+		seen[run] = true
+		runs = append(runs, fmt.Sprintf("%s (%s)", run, ds.Annotations[OwnerAnnotation]))
+	}
+	return runs
+}
+
+// checkHostPort refuses to start host network floaters on the port the host network floaters of another run
+// listen on, the nodes are shared and the second floater would fail to bind it.
+func (f *Floater) checkHostPort() error {
+	dss, err := f.Client.AppsV1().DaemonSets(f.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("linkctl floater run error, daemonset options failed: %v", err)
+	}
+	if conflicts := hostPortConflicts(dss.Items, f.Name, f.Port); len(conflicts) > 0 {
+		return fmt.Errorf("linkctl floater run error, the host network floaters %s already listen on port %s of the nodes, "+
+			"remove them with linkctl clean --run-id or pass another --port", strings.Join(conflicts, ", "), f.Port)
+	}
+	return nil
+}
+
+// hostPortConflicts lists the host network floater DaemonSets other than name listening on port.
+func hostPortConflicts(dss []appsv1.DaemonSet, name, port string) []string {
+	var conflicts []string
+	for i := range dss {
+		ds := &dss[i]
+		if ds.Name == name || !strings.HasPrefix(ds.Name, DefaultFloaterName) || !ds.Spec.Template.Spec.HostNetwork {
+			continue
+		}
+		dsPort := containerEnv(ds, "PORT")
+		if len(dsPort) == 0 {
+			dsPort = utils.DefaultPort
+		}
+		if dsPort != port {
+			continue
+		}
+		conflict := ds.Name
+		if run := ds.Labels[RunLabel]; len(run) > 0 {
+			conflict = fmt.Sprintf("%s of run %s (%s)", ds.Name, run, ds.Annotations[OwnerAnnotation])
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	return conflicts
+}
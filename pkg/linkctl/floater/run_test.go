@@ -0,0 +1,70 @@
+package floater
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func floaterDaemonSet(name, run, owner string) appsv1.DaemonSet {
+	ds := appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if len(run) > 0 {
+		ds.Labels = map[string]string{RunLabel: run}
+		ds.Annotations = map[string]string{OwnerAnnotation: owner}
+	}
+	return ds
+}
+
+func TestFloaterRuns(t *testing.T) {
+	dss := []appsv1.DaemonSet{
+		floaterDaemonSet("clusterlink-floater-1a2b3c4d", "1a2b3c4d", "alice@laptop"),
+		floaterDaemonSet("clusterlink-floater-host-1a2b3c4d", "1a2b3c4d", "alice@laptop"),
+		floaterDaemonSet("clusterlink-floater-5e6f7a8b", "5e6f7a8b", "bob@bastion"),
+		floaterDaemonSet("clusterlink-floater-host-5e6f7a8b", "5e6f7a8b", "bob@bastion"),
+		// deployed by an older linkctl
+		floaterDaemonSet("clusterlink-floater", "", ""),
+		floaterDaemonSet("clusterlink-operator", "", ""),
+	}
+
+	got := floaterRuns(dss, "1a2b3c4d")
+	want := []string{"5e6f7a8b (bob@bastion)", "clusterlink-floater (unlabeled)"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("floaterRuns() = %q, want %q", got, want)
+	}
+
+	if got = floaterRuns(dss[:2], "1a2b3c4d"); len(got) > 0 {
+		t.Errorf("floaterRuns() = %q, want no other run", got)
+	}
+}
+
+func TestHostPortConflicts(t *testing.T) {
+	hostFloater := func(name, run, port string) appsv1.DaemonSet {
+		ds := floaterDaemonSet(name, run, "bob@bastion")
+		ds.Spec.Template.Spec.HostNetwork = true
+		if len(port) > 0 {
+			ds.Spec.Template.Spec.Containers = []corev1.Container{{Name: "floater", Env: []corev1.EnvVar{{Name: "PORT", Value: port}}}}
+		}
+		return ds
+	}
+	dss := []appsv1.DaemonSet{
+		hostFloater("clusterlink-floater-host-1a2b3c4d", "1a2b3c4d", "8889"),
+		hostFloater("clusterlink-floater-host-5e6f7a8b", "5e6f7a8b", "8889"),
+		hostFloater("clusterlink-floater-host-9c0d1e2f", "9c0d1e2f", "8890"),
+		// deployed by an older linkctl, it listens on the default port
+		hostFloater("clusterlink-floater-host", "", ""),
+		floaterDaemonSet("clusterlink-floater-5e6f7a8b", "5e6f7a8b", "bob@bastion"),
+	}
+
+	got := hostPortConflicts(dss, "clusterlink-floater-host-1a2b3c4d", "8889")
+	want := []string{"clusterlink-floater-host-5e6f7a8b of run 5e6f7a8b (bob@bastion)", "clusterlink-floater-host"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("hostPortConflicts() = %q, want %q", got, want)
+	}
+
+	if got = hostPortConflicts(dss, "clusterlink-floater-host-1a2b3c4d", "8891"); len(got) > 0 {
+		t.Errorf("hostPortConflicts() = %q, want none on a free port", got)
+	}
+}
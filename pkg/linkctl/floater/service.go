@@ -0,0 +1,164 @@
+package floater
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	"github.com/kosmos.io/linkctl/pkg/linkctl/manifest"
+	"github.com/kosmos.io/linkctl/pkg/linkctl/util"
+)
+
+const (
+	DefaultFloaterServiceName = DefaultFloaterName + "-svc"
+	DefaultClusterDomain      = "cluster.local"
+)
+
+func (f *Floater) serviceReplace() manifest.FloaterServiceReplace {
+	return manifest.FloaterServiceReplace{
+		Namespace:   f.Namespace,
+		Name:        f.ServiceName(),
+		FloaterName: f.Name,
+		Port:        f.Port,
+	}
+}
+
+// CreateService puts a Service in front of the floaters and exports it to the other clusters.
+func (f *Floater) CreateService() error {
+	klog.Infof("create Clusterlink floater service, name: %s", f.ServiceName())
+	svc, err := util.GenerateService(manifest.ClusterlinkFloaterService, f.serviceReplace())
+	if err != nil {
+		return err
+	}
+	_, err = f.Client.CoreV1().Services(f.Namespace).Create(context.TODO(), svc, metav1.CreateOptions{})
+	if err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("linkctl floater run error, service options failed: %v", err)
+		}
+	}
+
+	return f.applyUnstructured(util.ServiceExportGVR, manifest.ClusterlinkFloaterServiceExport)
+}
+
+// CreateServiceImport imports the Service exported by the floaters of another cluster.
+func (f *Floater) CreateServiceImport() error {
+	klog.Infof("import Clusterlink floater service, name: %s", f.ServiceName())
+	return f.applyUnstructured(util.ServiceImportGVR, manifest.ClusterlinkFloaterServiceImport)
+}
+
+func (f *Floater) applyUnstructured(gvr schema.GroupVersionResource, template string) error {
+	obj, err := util.GenerateUnstructured(template, f.serviceReplace())
+	if err != nil {
+		return err
+	}
+	_, err = f.DynamicClient.Resource(gvr).Namespace(f.Namespace).Create(context.TODO(), obj, metav1.CreateOptions{})
+	if err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("linkctl floater run error, %s options failed: %v", gvr.Resource, err)
+		}
+	}
+
+	return nil
+}
+
+// WaitImportedService waits for the Kosmos MCS controller to create the imported Service and returns its IP,
+// the VIP of the ServiceImport takes precedence over the ClusterIP of the Service.
+func (f *Floater) WaitImportedService() (string, error) {
+	var serviceIP string
+	var lastErr error
+	pollErr := wait.PollImmediate(time.Second, time.Duration(f.PodWaitTime)*time.Second, func() (bool, error) {
+		serviceImport, err := f.DynamicClient.Resource(util.ServiceImportGVR).Namespace(f.Namespace).Get(context.TODO(), f.ServiceName(), metav1.GetOptions{})
+		if err != nil {
+			lastErr = err
+			return false, nil
+		}
+		if ips, _, _ := unstructured.NestedStringSlice(serviceImport.Object, "spec", "ips"); len(ips) > 0 {
+			serviceIP = ips[0]
+			return true, nil
+		}
+
+		svc, err := f.Client.CoreV1().Services(f.Namespace).Get(context.TODO(), f.ServiceName(), metav1.GetOptions{})
+		if err != nil {
+			lastErr = err
+			return false, nil
+		}
+		if len(svc.Spec.ClusterIP) == 0 || svc.Spec.ClusterIP == "None" {
+			lastErr = fmt.Errorf("service %s/%s has no cluster IP", f.Namespace, svc.Name)
+			return false, nil
+		}
+		serviceIP = svc.Spec.ClusterIP
+		return true, nil
+	})
+	if pollErr != nil {
+		return "", fmt.Errorf("wait for imported service %s/%s: %v: %v", f.Namespace, f.ServiceName(), pollErr, lastErr)
+	}
+
+	return serviceIP, nil
+}
+
+// ServiceDNSName is the name the floaters resolve the imported Service with.
+func (f *Floater) ServiceDNSName(clusterDomain string) string {
+	return fmt.Sprintf("%s.%s.svc.%s", f.ServiceName(), f.Namespace, clusterDomain)
+}
+
+// RemoveService removes the Service, its ServiceExport and its ServiceImport, whichever exist in the cluster.
+func (f *Floater) RemoveService() error {
+	for _, gvr := range []schema.GroupVersionResource{util.ServiceImportGVR, util.ServiceExportGVR} {
+		err := f.DynamicClient.Resource(gvr).Namespace(f.Namespace).Delete(context.TODO(), f.ServiceName(), metav1.DeleteOptions{})
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				return fmt.Errorf("linkctl floater run error, %s options failed: %v", gvr.Resource, err)
+			}
+		}
+	}
+
+	err := f.Client.CoreV1().Services(f.Namespace).Delete(context.TODO(), f.ServiceName(), metav1.DeleteOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("linkctl floater run error, service options failed: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// RunServices checks that every source floater reaches the floaters of the destination cluster through
+// the Service they export, both by its IP and by its DNS name. The Service is removed afterwards.
+func (o *CommandCheckOptions) RunServices() ([]*PrintCheckData, error) {
+	defer func() {
+		if err := o.SrcFloater.RemoveService(); err != nil {
+			klog.Warningf("remove imported floater service failed: %v", err)
+		}
+		if err := o.DstFloater.RemoveService(); err != nil {
+			klog.Warningf("remove exported floater service failed: %v", err)
+		}
+	}()
+
+	if err := o.DstFloater.CreateService(); err != nil {
+		return nil, err
+	}
+	if err := o.SrcFloater.CreateServiceImport(); err != nil {
+		return nil, err
+	}
+
+	srcPodInfos, err := o.SrcFloater.GetPodInfo()
+	if err != nil {
+		return nil, fmt.Errorf("get src cluster podInfos failed: %s", err)
+	}
+
+	ipTarget := checkTarget{DstNodeName: DefaultFloaterServiceName + "(IP)", Protocol: HTTP}
+	ipTarget.TargetIP, ipTarget.err = o.SrcFloater.WaitImportedService()
+	dnsTarget := checkTarget{DstNodeName: DefaultFloaterServiceName + "(DNS)", Protocol: HTTP, TargetIP: o.SrcFloater.ServiceDNSName(o.ClusterDomain)}
+	if ipTarget.err != nil {
+		dnsTarget.err = ipTarget.err
+	}
+
+	return o.runTargets(o.SrcFloater.SelectInfos(srcPodInfos), []checkTarget{ipTarget, dnsTarget}), nil
+}
@@ -0,0 +1,214 @@
+package floater
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	ctlutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/kosmos.io/linkctl/pkg/mesh"
+	"github.com/kosmos.io/linkctl/pkg/utils"
+)
+
+var statusExample = templates.Examples(i18n.T(`
+        # Print the latest results of the mesh started by linkctl mesh, e.g:
+        linkctl status --src-kubeconfig ~/kubeconfig/src-kubeconfig
+
+        # Print them as a source node x destination node grid, e.g:
+        linkctl status --src-kubeconfig ~/kubeconfig/src-kubeconfig --view matrix
+`))
+
+const (
+	AgentStateOK      = "OK"
+	AgentStateStale   = "STALE"
+	AgentStateMissing = "MISSING"
+)
+
+// staleRounds is the number of missed rounds after which the status of an agent is reported as stale.
+const staleRounds = 3
+
+type CommandStatusOptions struct {
+	CommandCheckOptions
+}
+
+func NewCmdStatus(configFlags *genericclioptions.ConfigFlags) *cobra.Command {
+	o := &CommandStatusOptions{}
+	o.ConfigFlags = configFlags
+
+	cmd := &cobra.Command{
+		Use:                   "status",
+		Short:                 i18n.T("Print the latest connectivity reported by the mesh agents"),
+		Long:                  "",
+		Example:               statusExample,
+		SilenceUsage:          true,
+		DisableFlagsInUseLine: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctlutil.CheckErr(o.Complete())
+			ctlutil.CheckErr(o.Validate())
+			ctlutil.CheckErr(o.Run())
+			return nil
+		},
+		Args: func(cmd *cobra.Command, args []string) error {
+			for _, arg := range args {
+				if len(arg) > 0 {
+					return fmt.Errorf("%q does not take any arguments, got %q", cmd.CommandPath(), args)
+				}
+			}
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&o.Namespace, "namespace", "n", utils.DefaultNamespace, "Kosmos namespace.")
+	flags.StringVar(&o.SrcKubeConfig, "src-kubeconfig", "", "Absolute path to the kubeconfig file of the cluster running the mesh, defaults to --kubeconfig, KUBECONFIG or ~/.kube/config.")
+	flags.StringVar(&o.SrcContext, "src-context", "", "Context of the cluster running the mesh, defaults to --context or the current context.")
+	flags.StringVar(&o.RunID, "run-id", "", "ID of the run of the mesh, defaults to the only mesh of the namespace.")
+	flags.Float64Var(&o.LatencyThreshold, "latency-threshold", 0, "Average RTT in milliseconds above which a reachable target is reported as slow, 0 disables it.")
+	flags.Float64Var(&o.LossThreshold, "loss-threshold", 0, "Packet loss percentage above which a reachable target is reported as slow.")
+	flags.StringVarP(&o.Output, "output", "o", "", "Output format, one of wide, json, yaml, csv or junit.")
+	flags.StringVar(&o.View, "view", ViewList, "View of the table output, list prints one row per target, matrix prints a source node x destination node grid.")
+	flags.Float64Var(&o.FailThreshold, "fail-threshold", 0, "Tolerated percentage of failed checks, the command exits with 2 above it and with 3 if any probe hit an exception.")
+
+	return cmd
+}
+
+func (o *CommandStatusOptions) Complete() error {
+	floater := &Floater{
+		Namespace:     o.Namespace,
+		Name:          runScopedName(DefaultFloaterName, o.RunID),
+		RunID:         o.RunID,
+		MeshConfigMap: MeshName(o.RunID),
+	}
+	if err := floater.completeFromClientConfig(newClientConfig(o.ConfigFlags, o.SrcKubeConfig, o.SrcContext, true)); err != nil {
+		return err
+	}
+	o.SrcFloater = floater
+
+	if len(o.RunID) > 0 {
+		return nil
+	}
+	meshes, err := floater.ListMeshes()
+	if err != nil {
+		return fmt.Errorf("list meshes failed: %v", err)
+	}
+	switch len(meshes) {
+	case 0:
+		return fmt.Errorf("no mesh in namespace %s, run linkctl mesh first", o.Namespace)
+	case 1:
+		floater.MeshConfigMap = meshes[0]
+	default:
+		runs := make([]string, 0, len(meshes))
+		for _, name := range meshes {
+			runs = append(runs, strings.TrimPrefix(name, DefaultMeshName+"-"))
+		}
+		return fmt.Errorf("namespace %s runs the meshes of the runs %s, select one with --run-id", o.Namespace, strings.Join(runs, ", "))
+	}
+
+	return nil
+}
+
+func (o *CommandStatusOptions) Validate() error {
+	if len(o.Namespace) == 0 {
+		return fmt.Errorf("namespace must be specified")
+	}
+	if o.FailThreshold < 0 || o.FailThreshold > 100 {
+		return fmt.Errorf("fail-threshold must be a percentage between 0 and 100, got %v", o.FailThreshold)
+	}
+	if !IsSupportedOutput(o.Output) {
+		return fmt.Errorf("output %q is not supported, must be one of %v", o.Output, SupportedOutputs)
+	}
+	if o.View != ViewList && o.View != ViewMatrix {
+		return fmt.Errorf("view %q is not supported, must be one of %s or %s", o.View, ViewList, ViewMatrix)
+	}
+	if o.View == ViewMatrix && IsStructuredOutput(o.Output) {
+		return fmt.Errorf("view %s can not be used with output %s", ViewMatrix, o.Output)
+	}
+	return nil
+}
+
+func (o *CommandStatusOptions) Run() error {
+	config, statuses, err := o.SrcFloater.GetMeshStatus()
+	if err != nil {
+		return err
+	}
+
+	var resultData []*PrintCheckData
+	for _, status := range statuses {
+		for i := range status.Results {
+			r := &status.Results[i]
+			resultData = append(resultData, &PrintCheckData{
+				Result:      r.Result,
+				SrcNodeName: status.NodeName,
+				DstNodeName: r.DstNodeName,
+				TargetIP:    r.TargetIP,
+			})
+		}
+	}
+
+	if !IsStructuredOutput(o.Output) {
+		printAgents(config, statuses)
+	}
+	o.printResultData(resultData)
+
+	return NewCheckSummary(resultData, o.Warning).ExitError(o.FailThreshold)
+}
+
+// printAgents tells when every agent of the mesh last reported, agents that did not report in a few
+// rounds are stale, peers without any report are missing.
+func printAgents(config *mesh.Config, statuses []*mesh.Status) {
+	reported := map[string]*mesh.Status{}
+	for _, status := range statuses {
+		reported[status.NodeName] = status
+	}
+	var nodeNames []string
+	for _, peer := range config.Peers {
+		nodeNames = append(nodeNames, peer.NodeName)
+	}
+	for _, status := range statuses {
+		if !containsString(nodeNames, status.NodeName) {
+			nodeNames = append(nodeNames, status.NodeName)
+		}
+	}
+	sort.Strings(nodeNames)
+
+	staleAfter := staleRounds*config.GetPeriod() + time.Duration(config.Timeout)*time.Second
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"NODE_NAME", "STATE", "LAST_REPORT", "TARGETS"})
+	for _, nodeName := range nodeNames {
+		status, ok := reported[nodeName]
+		if !ok {
+			table.Rich([]string{nodeName, AgentStateMissing, "-", "-"}, []tablewriter.Colors{
+				{tablewriter.Bold, tablewriter.FgCyanColor},
+			})
+			continue
+		}
+		age := time.Since(status.UpdateTime.Time)
+		state, color := AgentStateOK, tablewriter.FgGreenColor
+		if age > staleAfter {
+			state, color = AgentStateStale, tablewriter.FgYellowColor
+		}
+		table.Rich([]string{nodeName, state, age.Round(time.Second).String() + " ago", fmt.Sprint(len(status.Results))}, []tablewriter.Colors{
+			{tablewriter.Bold, color},
+		})
+	}
+	fmt.Println("MESH AGENTS")
+	table.Render()
+	fmt.Println("")
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
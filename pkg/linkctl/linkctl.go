@@ -36,14 +36,20 @@ func NewKosmosCtlCommand() *cobra.Command {
 		klog.Warning(err)
 	}
 
+	// the commands take the Kosmos namespace with their own --namespace
+	DefaultConfigFlags.Namespace = nil
+	DefaultConfigFlags.AddFlags(cmds.PersistentFlags())
+
 	groups := templates.CommandGroups{
 		{
 			Message: "Troubleshooting and Debugging Commands:",
 			Commands: []*cobra.Command{
-				floater.NewCmdCheck(),
-				floater.NewCmdResume(),
+				floater.NewCmdCheck(DefaultConfigFlags),
+				floater.NewCmdResume(DefaultConfigFlags),
 				floater.NewCmdInit(),
-				floater.NewCmdClean(),
+				floater.NewCmdClean(DefaultConfigFlags),
+				floater.NewCmdMesh(DefaultConfigFlags),
+				floater.NewCmdStatus(DefaultConfigFlags),
 			},
 		},
 	}
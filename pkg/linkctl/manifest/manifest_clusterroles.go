@@ -14,19 +14,6 @@ rules:
     verbs: ["get"]
 `
 
-	ClusterlinkFloaterClusterRole = `
-apiVersion: rbac.authorization.k8s.io/v1
-kind: ClusterRole
-metadata:
-  name: clusterlink-floater
-rules:
-  - apiGroups: ['*']
-    resources: ['*']
-    verbs: ["*"]
-  - nonResourceURLs: ['*']
-    verbs: ["get"]
-`
-
 	KosmosClusterRole = `
 apiVersion: rbac.authorization.k8s.io/v1
 kind: ClusterRole
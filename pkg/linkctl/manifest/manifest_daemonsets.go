@@ -5,29 +5,69 @@ const (
 apiVersion: apps/v1
 kind: DaemonSet
 metadata:
-  name: clusterlink-floater
+  name: {{ .Name }}
   namespace: {{ .Namespace }}
   labels:
-    app: clusterlink-floater
+    app: {{ .Name }}
+    kosmos.io/floater-run: "{{ .RunID }}"
+  annotations:
+    kosmos.io/floater-owner: "{{ .Owner }}"
 spec:
   replicas: 1
   selector:
     matchLabels:
-      app: clusterlink-floater
+      app: {{ .Name }}
   template:
     metadata:
       labels:
-        app: clusterlink-floater
+        app: {{ .Name }}
+        kosmos.io/floater-run: "{{ .RunID }}"
+      {{- if .EnableAnalysis }}
+      annotations:
+        prometheus.io/scrape: "true"
+        prometheus.io/port: "{{ .Port }}"
+        prometheus.io/path: /metrics
+      {{- end }}
     spec:
       hostNetwork: {{ .EnableHostNetwork }}
-      serviceAccountName: clusterlink-floater
+      dnsPolicy: ClusterFirstWithHostNet
+      serviceAccountName: {{ .ServiceAccountName }}
+      # only the mesh agents talk to the API server
+      automountServiceAccountToken: {{ if .MeshConfigMap }}true{{ else }}false{{ end }}
+      {{- if .NodeSelector }}
+      nodeSelector:
+        {{- range $key, $value := .NodeSelector }}
+        "{{ $key }}": "{{ $value }}"
+        {{- end }}
+      {{- end }}
       affinity:
         nodeAffinity:
           requiredDuringSchedulingIgnoredDuringExecution:
             nodeSelectorTerms:
+            {{- if .Nodes }}
+            # a node name field selector takes a single value, the terms are ORed
+            {{- range .Nodes }}
             - matchExpressions:
-              - key: kosmos.io/exclude
+              - key: {{ $.ExcludeLabel }}
                 operator: DoesNotExist
+              matchFields:
+              - key: metadata.name
+                operator: In
+                values: ["{{ . }}"]
+            {{- end }}
+            {{- else }}
+            - matchExpressions:
+              - key: {{ $.ExcludeLabel }}
+                operator: DoesNotExist
+              {{- if .ExcludeNodes }}
+              matchFields:
+              {{- range .ExcludeNodes }}
+              - key: metadata.name
+                operator: NotIn
+                values: ["{{ . }}"]
+              {{- end }}
+              {{- end }}
+            {{- end }}
       containers:
       - name: floater
         image: {{ .ImageRepository }}/clusterlink-floater:{{ .Version }}
@@ -41,6 +81,18 @@ spec:
             value: "{{ .Port }}"
           - name: "ENABLE_ANALYSIS"
             value: "{{ .EnableAnalysis }}"
+          - name: "PROBE_API"
+            value: "{{ .EnableProbeAPI }}"
+          - name: "MESH_CONFIGMAP"
+            value: "{{ .MeshConfigMap }}"
+          - name: "NODE_NAME"
+            valueFrom:
+              fieldRef:
+                fieldPath: spec.nodeName
+          - name: "POD_NAMESPACE"
+            valueFrom:
+              fieldRef:
+                fieldPath: metadata.namespace
       tolerations:
       - effect: NoSchedule
         operator: Exists
@@ -58,6 +110,21 @@ type DaemonSetReplace struct {
 	Version         string
 	Port            string
 
+	ServiceAccountName string
+	RunID              string
+	Owner              string
+
 	EnableHostNetwork bool `default:"false"`
 	EnableAnalysis    bool `default:"false"`
+	EnableProbeAPI    bool `default:"false"`
+
+	// MeshConfigMap turns the floaters into mesh agents reading their peers from this ConfigMap
+	MeshConfigMap string
+
+	// NodeSelector, Nodes and ExcludeNodes restrict the nodes of the floaters, Nodes takes precedence over ExcludeNodes,
+	// the nodes labeled with ExcludeLabel never run a floater
+	ExcludeLabel string
+	NodeSelector map[string]string
+	Nodes        []string
+	ExcludeNodes []string
 }
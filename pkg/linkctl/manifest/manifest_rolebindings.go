@@ -0,0 +1,30 @@
+package manifest
+
+const (
+	ClusterlinkFloaterRoleBinding = `
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: {{ .Name }}
+  namespace: {{ .Namespace }}
+  labels:
+    kosmos.io/floater-run: "{{ .RunID }}"
+  annotations:
+    kosmos.io/floater-owner: "{{ .Owner }}"
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: {{ .Name }}
+subjects:
+  - kind: ServiceAccount
+    name: {{ .Name }}
+    namespace: {{ .Namespace }}
+`
+)
+
+type RoleBindingReplace struct {
+	Namespace string
+	Name      string
+	RunID     string
+	Owner     string
+}
@@ -0,0 +1,42 @@
+package manifest
+
+const (
+	ClusterlinkFloaterRole = `
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: {{ .Name }}
+  namespace: {{ .Namespace }}
+  labels:
+    kosmos.io/floater-run: "{{ .RunID }}"
+  annotations:
+    kosmos.io/floater-owner: "{{ .Owner }}"
+rules:
+  - apiGroups: [""]
+    resources: ["configmaps"]
+    verbs: ["create"]
+  - apiGroups: [""]
+    resources: ["configmaps"]
+    resourceNames: ["{{ .ConfigMap }}"]
+    verbs: ["get"]
+  {{- if .StatusConfigMaps }}
+  - apiGroups: [""]
+    resources: ["configmaps"]
+    resourceNames:
+    {{- range .StatusConfigMaps }}
+      - "{{ . }}"
+    {{- end }}
+    verbs: ["update"]
+  {{- end }}
+`
+)
+
+type RoleReplace struct {
+	Namespace string
+	Name      string
+	RunID     string
+	Owner     string
+	// ConfigMap is the mesh config read by the agents, StatusConfigMaps the status they may update
+	ConfigMap        string
+	StatusConfigMaps []string
+}
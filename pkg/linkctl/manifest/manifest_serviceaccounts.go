@@ -29,8 +29,13 @@ metadata:
 apiVersion: v1
 kind: ServiceAccount
 metadata:
-  name:  clusterlink-floater
+  name: {{ .Name }}
   namespace: {{ .Namespace }}
+  labels:
+    kosmos.io/floater-run: "{{ .RunID }}"
+  annotations:
+    kosmos.io/floater-owner: "{{ .Owner }}"
+automountServiceAccountToken: false
 `
 
 	ClusterTreeServiceAccount = `
@@ -52,4 +57,9 @@ metadata:
 
 type ServiceAccountReplace struct {
 	Namespace string
+
+	// Name, RunID and Owner are only used by the floater ServiceAccount
+	Name  string
+	RunID string
+	Owner string
 }
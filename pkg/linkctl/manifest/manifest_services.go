@@ -33,3 +33,57 @@ spec:
 type ServiceReplace struct {
 	Namespace string
 }
+
+const (
+	ClusterlinkFloaterService = `
+apiVersion: v1
+kind: Service
+metadata:
+  name: {{ .Name }}
+  namespace: {{ .Namespace }}
+  labels:
+    app: {{ .FloaterName }}
+spec:
+  ports:
+    - name: http
+      port: {{ .Port }}
+      protocol: TCP
+      targetPort: {{ .Port }}
+  selector:
+    app: {{ .FloaterName }}
+  type: ClusterIP
+`
+
+	ClusterlinkFloaterServiceExport = `
+apiVersion: multicluster.x-k8s.io/v1alpha1
+kind: ServiceExport
+metadata:
+  name: {{ .Name }}
+  namespace: {{ .Namespace }}
+  labels:
+    app: {{ .FloaterName }}
+`
+
+	ClusterlinkFloaterServiceImport = `
+apiVersion: multicluster.x-k8s.io/v1alpha1
+kind: ServiceImport
+metadata:
+  name: {{ .Name }}
+  namespace: {{ .Namespace }}
+  labels:
+    app: {{ .FloaterName }}
+spec:
+  type: ClusterSetIP
+  ports:
+    - name: http
+      port: {{ .Port }}
+      protocol: TCP
+`
+)
+
+type FloaterServiceReplace struct {
+	Namespace   string
+	Name        string
+	FloaterName string
+	Port        string
+}
@@ -9,15 +9,20 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
 )
 
 var (
 	ClusterGVR     = schema.GroupVersionResource{Group: "kosmos.io", Version: "v1alpha1", Resource: "clusters"}
 	ClusterNodeGVR = schema.GroupVersionResource{Group: "kosmos.io", Version: "v1alpha1", Resource: "clusternodes"}
 	NodeConfigGVR  = schema.GroupVersionResource{Group: "kosmos.io", Version: "v1alpha1", Resource: "nodeconfigs"}
+
+	ServiceExportGVR = schema.GroupVersionResource{Group: "multicluster.x-k8s.io", Version: "v1alpha1", Resource: "serviceexports"}
+	ServiceImportGVR = schema.GroupVersionResource{Group: "multicluster.x-k8s.io", Version: "v1alpha1", Resource: "serviceimports"}
 )
 
 func GenerateDeployment(deployTemplate string, obj interface{}) (*appsv1.Deployment, error) {
@@ -105,6 +110,40 @@ func GenerateClusterRoleBinding(crbTemplate string, obj interface{}) (*rbacv1.Cl
 	return crbStruct, nil
 }
 
+func GenerateRole(roleTemplate string, obj interface{}) (*rbacv1.Role, error) {
+	roleBytes, err := parseTemplate(roleTemplate, obj)
+	if err != nil {
+		return nil, fmt.Errorf("linkctl parsing Role template exception, error: %v", err)
+	} else if roleBytes == nil {
+		return nil, fmt.Errorf("linkctl get Role template exception, value is empty")
+	}
+
+	roleStruct := &rbacv1.Role{}
+
+	if err = runtime.DecodeInto(scheme.Codecs.UniversalDecoder(), roleBytes, roleStruct); err != nil {
+		return nil, fmt.Errorf("linkctl decode roleBytes error: %v", err)
+	}
+
+	return roleStruct, nil
+}
+
+func GenerateRoleBinding(rbTemplate string, obj interface{}) (*rbacv1.RoleBinding, error) {
+	rbBytes, err := parseTemplate(rbTemplate, obj)
+	if err != nil {
+		return nil, fmt.Errorf("linkctl parsing RoleBinding template exception, error: %v", err)
+	} else if rbBytes == nil {
+		return nil, fmt.Errorf("linkctl get RoleBinding template exception, value is empty")
+	}
+
+	rbStruct := &rbacv1.RoleBinding{}
+
+	if err = runtime.DecodeInto(scheme.Codecs.UniversalDecoder(), rbBytes, rbStruct); err != nil {
+		return nil, fmt.Errorf("linkctl decode rbBytes error: %v", err)
+	}
+
+	return rbStruct, nil
+}
+
 func GenerateCustomResourceDefinition(crdTemplate string, obj interface{}) (*apiextensionsv1.CustomResourceDefinition, error) {
 	crdBytes, err := parseTemplate(crdTemplate, obj)
 	if err != nil {
@@ -133,6 +172,23 @@ func GenerateCustomResource(crdTemplate string, obj interface{}) ([]byte, error)
 	return crBytes, nil
 }
 
+func GenerateUnstructured(template string, obj interface{}) (*unstructured.Unstructured, error) {
+	bs, err := parseTemplate(template, obj)
+	if err != nil {
+		return nil, fmt.Errorf("linkctl parsing unstructured template exception, error: %v", err)
+	} else if bs == nil {
+		return nil, fmt.Errorf("linkctl get unstructured template exception, value is empty")
+	}
+
+	o := &unstructured.Unstructured{}
+
+	if err = yaml.Unmarshal(bs, &o.Object); err != nil {
+		return nil, fmt.Errorf("linkctl decode unstructured bytes error: %v", err)
+	}
+
+	return o, nil
+}
+
 func parseTemplate(strTmpl string, obj interface{}) ([]byte, error) {
 	var buf bytes.Buffer
 	tmpl, err := template.New("template").Parse(strTmpl)
@@ -125,6 +125,40 @@ func WaitDeploymentReady(c kubernetes.Interface, d *appsv1.Deployment, timeoutSe
 	return nil
 }
 
+// WaitDaemonSetReady  wait every pod of the daemonset to run its current template or timeout.
+func WaitDaemonSetReady(c kubernetes.Interface, namespace, name string, timeoutSeconds int) error {
+	var lastErr error
+
+	pollError := wait.PollImmediate(time.Second, time.Duration(timeoutSeconds)*time.Second, func() (bool, error) {
+		ds, err := c.AppsV1().DaemonSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			lastErr = err
+			return false, nil
+		}
+		if ds.Generation != ds.Status.ObservedGeneration {
+			lastErr = fmt.Errorf("current generation %d, observed generation %d",
+				ds.Generation, ds.Status.ObservedGeneration)
+			return false, nil
+		}
+		if ds.Status.UpdatedNumberScheduled < ds.Status.DesiredNumberScheduled {
+			lastErr = fmt.Errorf("%d of %d pods run the desired template spec",
+				ds.Status.UpdatedNumberScheduled, ds.Status.DesiredNumberScheduled)
+			return false, nil
+		}
+		if ds.Status.NumberAvailable < ds.Status.DesiredNumberScheduled || ds.Status.NumberUnavailable > 0 {
+			lastErr = fmt.Errorf("expected %d pods, got %d available pods",
+				ds.Status.DesiredNumberScheduled, ds.Status.NumberAvailable)
+			return false, nil
+		}
+		return true, nil
+	})
+	if pollError != nil {
+		return fmt.Errorf("wait for DaemonSet(%s/%s) ready: %v: %v", namespace, name, pollError, lastErr)
+	}
+
+	return nil
+}
+
 // MapToString  labels to string.
 func MapToString(labels map[string]string) string {
 	v := new(bytes.Buffer)
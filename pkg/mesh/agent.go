@@ -0,0 +1,123 @@
+package mesh
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"github.com/kosmos.io/linkctl/pkg/probe"
+)
+
+// maxConcurrentProbes bounds the probes an agent runs at the same time.
+const maxConcurrentProbes = 10
+
+// Agent probes the peers listed in the mesh ConfigMap on every period and publishes the results of its node.
+type Agent struct {
+	Client    kubernetes.Interface
+	Namespace string
+	// Name of the config ConfigMap
+	Name     string
+	NodeName string
+
+	// Metrics is optional, it exports the results of every round when set
+	Metrics *Metrics
+}
+
+func (a *Agent) Run(ctx context.Context) {
+	for {
+		period := DefaultPeriod * time.Second
+		config, err := a.getConfig(ctx)
+		if err != nil {
+			klog.Errorf("get mesh config failed: %v", err)
+		} else {
+			period = config.GetPeriod()
+			status := a.probePeers(config)
+			if a.Metrics != nil {
+				a.Metrics.Observe(config.Protocol, status)
+			}
+			if err = a.updateStatus(ctx, status); err != nil {
+				klog.Errorf("update mesh status failed: %v", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(period):
+		}
+	}
+}
+
+func (a *Agent) getConfig(ctx context.Context) (*Config, error) {
+	cm, err := a.Client.CoreV1().ConfigMaps(a.Namespace).Get(ctx, a.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	config := &Config{}
+	if err = ParseConfigMap(cm, ConfigKey, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (a *Agent) probePeers(config *Config) *Status {
+	status := &Status{NodeName: a.NodeName}
+
+	var requests []*probe.Request
+	for _, peer := range config.Peers {
+		if peer.NodeName == a.NodeName {
+			continue
+		}
+		for _, ip := range peer.IPs {
+			requests = append(requests, &probe.Request{
+				Protocol:   config.Protocol,
+				TargetIP:   ip,
+				Port:       config.Port,
+				Timeout:    config.Timeout,
+				Count:      config.Count,
+				Interval:   config.Interval,
+				PacketSize: config.PacketSize,
+			})
+			status.Results = append(status.Results, PeerResult{DstNodeName: peer.NodeName, TargetIP: ip})
+		}
+	}
+
+	var wg sync.WaitGroup
+	ch := make(chan struct{}, maxConcurrentProbes)
+	for i := range requests {
+		index := i
+		ch <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status.Results[index].Result = *probe.Run(requests[index])
+			<-ch
+		}()
+	}
+	wg.Wait()
+
+	status.UpdateTime = metav1.Now()
+	return status
+}
+
+func (a *Agent) updateStatus(ctx context.Context, status *Status) error {
+	cm, err := NewConfigMap(a.Namespace, a.Name, StatusConfigMapName(a.Name, a.NodeName), StatusKey, status)
+	if err != nil {
+		return err
+	}
+
+	_, err = a.Client.CoreV1().ConfigMaps(a.Namespace).Create(ctx, cm, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		_, err = a.Client.CoreV1().ConfigMaps(a.Namespace).Update(ctx, cm, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("write configmap %s/%s failed: %v", cm.Namespace, cm.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package mesh
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kosmos.io/linkctl/pkg/results"
+)
+
+const (
+	// EnvConfigMap names the ConfigMap holding the mesh Config, the floater only runs the agent when it is set
+	EnvConfigMap = "MESH_CONFIGMAP"
+	// EnvNamespace is the namespace of the floater pod
+	EnvNamespace = "POD_NAMESPACE"
+	// EnvEnableAnalysis serves the metrics of the floater, including the ones of the mesh agent
+	EnvEnableAnalysis = "ENABLE_ANALYSIS"
+
+	// Label is set on the config and the status ConfigMaps of a mesh, its value is the name of the config ConfigMap
+	Label = "kosmos.io/floater-mesh"
+
+	ConfigKey = "config.json"
+	StatusKey = "status.json"
+
+	DefaultPeriod = 60
+)
+
+// Peer is a floater probed by the other floaters of the mesh.
+type Peer struct {
+	NodeName string   `json:"nodeName"`
+	IPs      []string `json:"ips"`
+}
+
+// Config tells the agents what to probe and how often.
+type Config struct {
+	// Period between two rounds of probes in seconds
+	Period int `json:"period"`
+
+	Protocol string `json:"protocol"`
+	Port     string `json:"port"`
+	// Timeout of every probe in seconds
+	Timeout int `json:"timeout"`
+
+	Count      int     `json:"count"`
+	Interval   float64 `json:"interval"`
+	PacketSize int     `json:"packetSize"`
+
+	Peers []Peer `json:"peers"`
+}
+
+func (c *Config) GetPeriod() time.Duration {
+	if c.Period < 1 {
+		return DefaultPeriod * time.Second
+	}
+	return time.Duration(c.Period) * time.Second
+}
+
+// PeerResult is the latest result of probing one IP of a peer.
+type PeerResult struct {
+	results.Result
+	DstNodeName string `json:"dstNodeName"`
+	TargetIP    string `json:"targetIP"`
+}
+
+// Status is published by the agent of every node after each round of probes.
+type Status struct {
+	NodeName   string       `json:"nodeName"`
+	UpdateTime metav1.Time  `json:"updateTime"`
+	Results    []PeerResult `json:"results"`
+}
+
+// StatusConfigMapName is the name of the ConfigMap holding the status of nodeName.
+func StatusConfigMapName(name, nodeName string) string {
+	return fmt.Sprintf("%s-%s", name, nodeName)
+}
+
+// NewConfigMap stores obj as json under key in a ConfigMap labelled for the mesh name.
+func NewConfigMap(namespace, name, cmName, key string, obj interface{}) (*corev1.ConfigMap, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cmName,
+			Namespace: namespace,
+			Labels:    map[string]string{Label: name},
+		},
+		Data: map[string]string{key: string(data)},
+	}, nil
+}
+
+// ParseConfigMap decodes the json stored under key.
+func ParseConfigMap(cm *corev1.ConfigMap, key string, obj interface{}) error {
+	data, ok := cm.Data[key]
+	if !ok {
+		return fmt.Errorf("configmap %s/%s has no %s", cm.Namespace, cm.Name, key)
+	}
+	if err := json.Unmarshal([]byte(data), obj); err != nil {
+		return fmt.Errorf("decode %s of configmap %s/%s failed: %v", key, cm.Namespace, cm.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package mesh
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/kosmos.io/linkctl/pkg/results"
+)
+
+const metricsNamespace = "clusterlink_floater"
+
+var peerLabels = []string{"src_node", "dst_node", "target_ip", "protocol"}
+
+// Metrics exports the latest result of every peer probed by the agent.
+type Metrics struct {
+	success     *prometheus.GaugeVec
+	rtt         *prometheus.HistogramVec
+	loss        *prometheus.GaugeVec
+	lastSuccess *prometheus.GaugeVec
+
+	// label values observed in the previous round, the series of peers gone since then are deleted
+	observed map[[4]string]bool
+}
+
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		success: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "probe_success",
+			Help:      "Whether the last probe of the peer succeeded.",
+		}, peerLabels),
+		rtt: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "probe_rtt_seconds",
+			Help:      "Round trip time to the peer, the average echo RTT for icmp and the probe duration otherwise.",
+			Buckets:   prometheus.ExponentialBuckets(0.0001, 2, 16),
+		}, peerLabels),
+		loss: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "probe_loss_ratio",
+			Help:      "Ratio of the echo requests lost by the last probe of the peer.",
+		}, peerLabels),
+		lastSuccess: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "probe_last_success_timestamp_seconds",
+			Help:      "Unix time of the last successful probe of the peer.",
+		}, peerLabels),
+		observed: map[[4]string]bool{},
+	}
+	reg.MustRegister(m.success, m.rtt, m.loss, m.lastSuccess)
+	return m
+}
+
+// Observe records a round of probes published as status.
+func (m *Metrics) Observe(protocol string, status *Status) {
+	observed := map[[4]string]bool{}
+	for i := range status.Results {
+		r := &status.Results[i]
+		labels := [4]string{status.NodeName, r.DstNodeName, r.TargetIP, protocol}
+		observed[labels] = true
+		values := labels[:]
+
+		if r.Status != results.CommandSuccessed {
+			m.success.WithLabelValues(values...).Set(0)
+			// an unreachable peer loses everything, a probe that could not run tells nothing about the link
+			if r.Status == results.CommandFailed {
+				m.loss.WithLabelValues(values...).Set(1)
+			}
+			continue
+		}
+
+		m.success.WithLabelValues(values...).Set(1)
+		m.lastSuccess.WithLabelValues(values...).Set(float64(status.UpdateTime.Unix()))
+		if r.Statistics != nil {
+			m.rtt.WithLabelValues(values...).Observe(r.Statistics.Avg / 1000)
+			m.loss.WithLabelValues(values...).Set(r.Statistics.Loss / 100)
+		} else {
+			m.rtt.WithLabelValues(values...).Observe(r.Elapsed.Seconds())
+			m.loss.WithLabelValues(values...).Set(0)
+		}
+	}
+
+	for labels := range m.observed {
+		if !observed[labels] {
+			m.success.DeleteLabelValues(labels[:]...)
+			m.rtt.DeleteLabelValues(labels[:]...)
+			m.loss.DeleteLabelValues(labels[:]...)
+			m.lastSuccess.DeleteLabelValues(labels[:]...)
+		}
+	}
+	m.observed = observed
+}
@@ -0,0 +1,41 @@
+package probe
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/kosmos.io/linkctl/pkg/results"
+)
+
+// runDNS resolves the name against the server TargetIP only, bypassing the resolv.conf of the floater.
+func runDNS(r *Request) *results.Result {
+	port := r.Port
+	if len(port) == 0 {
+		port = "53"
+	}
+	server := net.JoinHostPort(r.TargetIP, port)
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			d := net.Dialer{}
+			return d.DialContext(ctx, network, server)
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout())
+	defer cancel()
+
+	start := time.Now()
+	addresses, err := resolver.LookupHost(ctx, r.Name)
+	elapsed := time.Since(start)
+	if err != nil {
+		result := failed("resolve %s by %s failed: %v", r.Name, server, err)
+		result.Elapsed = elapsed
+		return result
+	}
+
+	result := results.NewDNSResult(r.Name, r.TargetIP, r.Expect, addresses)
+	result.Elapsed = elapsed
+	return result
+}
@@ -0,0 +1,276 @@
+package probe
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/kosmos.io/linkctl/pkg/results"
+	"github.com/kosmos.io/linkctl/pkg/utils"
+)
+
+const (
+	icmpv4EchoRequest = 8
+	icmpv4EchoReply   = 0
+	icmpv6EchoRequest = 128
+	icmpv6EchoReply   = 129
+)
+
+// echoID distinguishes the concurrent probes, every raw socket receives all the echo replies of the host.
+var echoID uint32
+
+type pinger struct {
+	conn     net.PacketConn
+	target   *net.IPAddr
+	ipv6     bool
+	id       uint16
+	seq      uint16
+	deadline time.Time
+}
+
+func newPinger(targetIP string, deadline time.Time) (*pinger, error) {
+	ip := net.ParseIP(targetIP)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid target %q", targetIP)
+	}
+	p := &pinger{
+		target:   &net.IPAddr{IP: ip},
+		ipv6:     utils.IsIPv6(targetIP),
+		id:       uint16(atomic.AddUint32(&echoID, 1)),
+		deadline: deadline,
+	}
+
+	network := "ip4:icmp"
+	if p.ipv6 {
+		network = "ip6:ipv6-icmp"
+	}
+	conn, err := net.ListenPacket(network, "")
+	if err != nil {
+		return nil, err
+	}
+	p.conn = conn
+
+	return p, nil
+}
+
+func (p *pinger) Close() error {
+	return p.conn.Close()
+}
+
+// headerSize is the size of the IP and ICMP headers in front of the echo payload.
+func (p *pinger) headerSize() int {
+	if p.ipv6 {
+		return 40 + 8
+	}
+	return 20 + 8
+}
+
+func (p *pinger) message(size int) []byte {
+	b := make([]byte, 8+size)
+	b[0] = icmpv4EchoRequest
+	if p.ipv6 {
+		b[0] = icmpv6EchoRequest
+	}
+	binary.BigEndian.PutUint16(b[4:], p.id)
+	binary.BigEndian.PutUint16(b[6:], p.seq)
+	for i := 8; i < len(b); i++ {
+		b[i] = byte(i)
+	}
+	// the kernel computes the checksum of ICMPv6
+	if !p.ipv6 {
+		binary.BigEndian.PutUint16(b[2:], checksum(b))
+	}
+	return b
+}
+
+func checksum(b []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(b); i += 2 {
+		sum += uint32(b[i])<<8 | uint32(b[i+1])
+	}
+	if len(b)%2 == 1 {
+		sum += uint32(b[len(b)-1]) << 8
+	}
+	for sum>>16 != 0 {
+		sum = sum&0xffff + sum>>16
+	}
+	return ^uint16(sum)
+}
+
+// echo sends one echo request with a payload of size bytes and waits up to wait for its reply.
+func (p *pinger) echo(size int, wait time.Duration) (time.Duration, error) {
+	p.seq++
+	deadline := time.Now().Add(wait)
+	if deadline.After(p.deadline) {
+		deadline = p.deadline
+	}
+	if err := p.conn.SetReadDeadline(deadline); err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	if _, err := p.conn.WriteTo(p.message(size), p.target); err != nil {
+		return 0, err
+	}
+
+	reply := byte(icmpv4EchoReply)
+	if p.ipv6 {
+		reply = icmpv6EchoReply
+	}
+	buf := make([]byte, 65535)
+	for {
+		n, from, err := p.conn.ReadFrom(buf)
+		if err != nil {
+			return 0, err
+		}
+		if n < 8 || buf[0] != reply {
+			continue
+		}
+		if addr, ok := from.(*net.IPAddr); !ok || !addr.IP.Equal(p.target.IP) {
+			continue
+		}
+		if binary.BigEndian.Uint16(buf[4:]) != p.id || binary.BigEndian.Uint16(buf[6:]) != p.seq {
+			continue
+		}
+		return time.Since(start), nil
+	}
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func runICMP(r *Request) *results.Result {
+	deadline := time.Now().Add(r.timeout())
+	p, err := newPinger(r.TargetIP, deadline)
+	if err != nil {
+		return results.ParseError(err)
+	}
+	defer p.Close()
+
+	interval := time.Duration(r.Interval * float64(time.Second))
+	stats := &results.PingStatistics{}
+	var sum, sumSquare float64
+	var log strings.Builder
+	for i := 0; i < r.Count && time.Now().Before(deadline); i++ {
+		start := time.Now()
+		stats.Transmitted++
+		rtt, err := p.echo(r.PacketSize, time.Until(deadline))
+		if err == nil {
+			ms := float64(rtt.Microseconds()) / 1000
+			if stats.Received == 0 || ms < stats.Min {
+				stats.Min = ms
+			}
+			if ms > stats.Max {
+				stats.Max = ms
+			}
+			sum += ms
+			sumSquare += ms * ms
+			stats.Received++
+			fmt.Fprintf(&log, "reply from %s: seq=%d time=%.3f ms\n", r.TargetIP, p.seq, ms)
+		} else if isTimeout(err) {
+			fmt.Fprintf(&log, "no reply from %s: seq=%d\n", r.TargetIP, p.seq)
+		} else {
+			fmt.Fprintf(&log, "echo to %s failed: seq=%d %v\n", r.TargetIP, p.seq, err)
+		}
+		if i < r.Count-1 {
+			time.Sleep(interval - time.Since(start))
+		}
+	}
+
+	stats.Loss = float64(stats.Transmitted-stats.Received) * 100 / float64(stats.Transmitted)
+	if stats.Received > 0 {
+		stats.Avg = sum / float64(stats.Received)
+		stats.Mdev = math.Sqrt(math.Max(sumSquare/float64(stats.Received)-stats.Avg*stats.Avg, 0))
+	}
+	fmt.Fprintf(&log, "%d packets transmitted, %d packets received, %g%% packet loss", stats.Transmitted, stats.Received, stats.Loss)
+
+	status := results.CommandSuccessed
+	if stats.Received == 0 {
+		status = results.CommandFailed
+	}
+	return &results.Result{
+		Status:     status,
+		ResultStr:  log.String(),
+		Statistics: stats,
+	}
+}
+
+// interfaceMTU returns the MTU of the interface the kernel routes targetIP through.
+func interfaceMTU(targetIP string) (int, error) {
+	// connecting an udp socket only resolves the route, nothing is sent
+	conn, err := net.Dial("udp", net.JoinHostPort(targetIP, "9"))
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	local := conn.LocalAddr().(*net.UDPAddr).IP
+
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return 0, err
+	}
+	for _, iface := range interfaces {
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.Equal(local) {
+				return iface.MTU, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("no interface with address %s", local)
+}
+
+// runMTU binary-searches the largest don't-fragment echo request that gets a reply.
+func runMTU(r *Request) *results.Result {
+	mtu, err := interfaceMTU(r.TargetIP)
+	if err != nil {
+		return results.ParseError(err)
+	}
+
+	p, err := newPinger(r.TargetIP, time.Now().Add(r.timeout()))
+	if err != nil {
+		return results.ParseError(err)
+	}
+	defer p.Close()
+	if err = setDontFragment(p.conn, p.ipv6); err != nil {
+		return results.ParseError(err)
+	}
+
+	lo, hi, best := 0, mtu-p.headerSize(), -1
+	for lo <= hi && time.Now().Before(p.deadline) {
+		mid := (lo + hi) / 2
+		_, err = p.echo(mid, time.Second)
+		if err == nil {
+			best, lo = mid, mid+1
+			continue
+		}
+		// EMSGSIZE means the size is above the path MTU known by the kernel
+		if !isTimeout(err) && !errors.Is(err, syscall.EMSGSIZE) {
+			return results.ParseError(err)
+		}
+		hi = mid - 1
+	}
+
+	if best < 0 {
+		return failed("no don't-fragment echo reply from %s, interface mtu: %d", r.TargetIP, mtu)
+	}
+	return &results.Result{
+		Status:    results.CommandSuccessed,
+		ResultStr: fmt.Sprintf("interface mtu: %d\nmax payload: %d", mtu, best),
+		MTU: &results.MTUStatistics{
+			PathMTU:      best + p.headerSize(),
+			InterfaceMTU: mtu,
+		},
+	}
+}
@@ -0,0 +1,252 @@
+package probe
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kosmos.io/linkctl/pkg/results"
+)
+
+const (
+	ICMP = "icmp"
+	TCP  = "tcp"
+	UDP  = "udp"
+	HTTP = "http"
+	MTU  = "mtu"
+	DNS  = "dns"
+)
+
+const (
+	// Path of the probe API served by the floater.
+	Path = "probe"
+	// EnvEnable turns the probe API on, it is only set on the floaters of a check with --probe-mode api since the
+	// API probes the targets of its caller
+	EnvEnable = "PROBE_API"
+)
+
+// Request describes one probe run natively by the floater.
+type Request struct {
+	Protocol string
+	TargetIP string
+	Port     string
+	// Timeout of the whole probe in seconds
+	Timeout int
+
+	Count      int
+	Interval   float64
+	PacketSize int
+
+	// Name is resolved by the dns probe against the server TargetIP, the answer must contain Expect when it is set
+	Name   string
+	Expect string
+}
+
+// Params encodes the request as the query of the probe API.
+func (r *Request) Params() map[string]string {
+	return map[string]string{
+		"protocol":   r.Protocol,
+		"target":     r.TargetIP,
+		"port":       r.Port,
+		"timeout":    strconv.Itoa(r.Timeout),
+		"count":      strconv.Itoa(r.Count),
+		"interval":   strconv.FormatFloat(r.Interval, 'f', -1, 64),
+		"packetSize": strconv.Itoa(r.PacketSize),
+		"name":       r.Name,
+		"expect":     r.Expect,
+	}
+}
+
+func ParseRequest(query url.Values) (*Request, error) {
+	r := &Request{
+		Protocol: query.Get("protocol"),
+		TargetIP: query.Get("target"),
+		Port:     query.Get("port"),
+		Name:     query.Get("name"),
+		Expect:   query.Get("expect"),
+		Timeout:  3,
+		Count:    1,
+		Interval: 1,
+	}
+	if err := validTarget(r.Protocol, r.TargetIP); err != nil {
+		return nil, err
+	}
+	if r.Protocol == DNS && len(r.Name) == 0 {
+		return nil, fmt.Errorf("the dns probe needs a name to resolve")
+	}
+
+	var err error
+	if v := query.Get("timeout"); len(v) > 0 {
+		if r.Timeout, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("invalid timeout %q: %v", v, err)
+		}
+	}
+	if v := query.Get("count"); len(v) > 0 {
+		if r.Count, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("invalid count %q: %v", v, err)
+		}
+	}
+	if v := query.Get("interval"); len(v) > 0 {
+		if r.Interval, err = strconv.ParseFloat(v, 64); err != nil {
+			return nil, fmt.Errorf("invalid interval %q: %v", v, err)
+		}
+	}
+	if v := query.Get("packetSize"); len(v) > 0 {
+		if r.PacketSize, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("invalid packetSize %q: %v", v, err)
+		}
+	}
+	if r.Timeout < 1 || r.Count < 1 || r.Interval <= 0 || r.PacketSize < 0 {
+		return nil, fmt.Errorf("timeout and count must be at least 1, interval must be positive and packetSize must not be negative")
+	}
+
+	return r, nil
+}
+
+// serviceNameReg matches the DNS name of a Service, <service>.<namespace>.svc.<cluster domain>.
+var serviceNameReg = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?\.[a-z0-9]([-a-z0-9]*[a-z0-9])?\.svc(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)+\.?$`)
+
+// validTarget keeps the API from probing the floater itself or the link-local metadata services, only the
+// http probe takes a name, which must be the DNS name of a Service.
+func validTarget(protocol, target string) error {
+	ip := net.ParseIP(target)
+	if ip == nil {
+		if protocol == HTTP && serviceNameReg.MatchString(target) {
+			return nil
+		}
+		return fmt.Errorf("invalid target %q", target)
+	}
+	if ip.IsLoopback() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() {
+		return fmt.Errorf("target %s is not a pod, node or service IP", target)
+	}
+	return nil
+}
+
+// Authorize restricts the request to the ports linkctl probes, the tcp, udp and http probes only reach the
+// floater port and the dns probe the dns port, so that the API does not relay to other services of the targets.
+func (r *Request) Authorize(floaterPort string) error {
+	switch r.Protocol {
+	case TCP, UDP, HTTP:
+		if r.Port != floaterPort {
+			return fmt.Errorf("the %s probe only reaches the floater port %s, got %q", r.Protocol, floaterPort, r.Port)
+		}
+	case DNS:
+		if len(r.Port) > 0 && r.Port != "53" {
+			return fmt.Errorf("the dns probe only reaches port 53, got %q", r.Port)
+		}
+	}
+	return nil
+}
+
+func (r *Request) timeout() time.Duration {
+	return time.Duration(r.Timeout) * time.Second
+}
+
+func (r *Request) hostPort() string {
+	return net.JoinHostPort(r.TargetIP, r.Port)
+}
+
+// Run executes the probe in the current process, the result has the same shape as the one parsed from
+// the exec based commands.
+func Run(r *Request) *results.Result {
+	start := time.Now()
+
+	var result *results.Result
+	switch r.Protocol {
+	case ICMP:
+		result = runICMP(r)
+	case TCP:
+		result = runTCP(r)
+	case UDP:
+		result = runUDP(r)
+	case HTTP:
+		result = runHTTP(r)
+	case MTU:
+		result = runMTU(r)
+	case DNS:
+		result = runDNS(r)
+	default:
+		result = results.ParseError(fmt.Errorf("unsupported protocol %q", r.Protocol))
+	}
+	if result.Elapsed == 0 {
+		result.Elapsed = time.Since(start)
+	}
+
+	return result
+}
+
+func failed(format string, a ...any) *results.Result {
+	return &results.Result{
+		Status:    results.CommandFailed,
+		ResultStr: fmt.Sprintf(format, a...),
+	}
+}
+
+func succeeded(format string, a ...any) *results.Result {
+	return &results.Result{
+		Status:    results.CommandSuccessed,
+		ResultStr: fmt.Sprintf(format, a...),
+	}
+}
+
+func runTCP(r *Request) *results.Result {
+	conn, err := net.DialTimeout("tcp", r.hostPort(), r.timeout())
+	if err != nil {
+		return failed("tcp connect %s failed: %v", r.hostPort(), err)
+	}
+	defer conn.Close()
+
+	return succeeded("tcp connected %s", r.hostPort())
+}
+
+func runUDP(r *Request) *results.Result {
+	conn, err := net.DialTimeout("udp", r.hostPort(), r.timeout())
+	if err != nil {
+		return failed("udp dial %s failed: %v", r.hostPort(), err)
+	}
+	defer conn.Close()
+
+	if err = conn.SetDeadline(time.Now().Add(r.timeout())); err != nil {
+		return results.ParseError(err)
+	}
+	if _, err = conn.Write([]byte(results.UDPEchoPayload)); err != nil {
+		return failed("udp send to %s failed: %v", r.hostPort(), err)
+	}
+
+	buf := make([]byte, len(results.UDPEchoPayload))
+	n, err := conn.Read(buf)
+	if err != nil {
+		return failed("no udp echo received from %s: %v", r.hostPort(), err)
+	}
+	if string(buf[:n]) != results.UDPEchoPayload {
+		return failed("unexpected udp echo from %s: %q", r.hostPort(), buf[:n])
+	}
+
+	return succeeded("udp echo received from %s", r.hostPort())
+}
+
+func runHTTP(r *Request) *results.Result {
+	target := url.URL{Scheme: "http", Host: r.hostPort(), Path: "/"}
+	client := &http.Client{Timeout: r.timeout()}
+	resp, err := client.Get(target.String())
+	if err != nil {
+		return failed("http get %s failed: %v", target.String(), err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return failed("http read %s failed: %v", target.String(), err)
+	}
+	if strings.TrimSpace(string(body)) != "OK" {
+		return failed("http get %s returned %d: %s", target.String(), resp.StatusCode, body)
+	}
+
+	return succeeded("OK")
+}
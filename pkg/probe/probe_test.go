@@ -0,0 +1,146 @@
+package probe
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestParseRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    *Request
+		wantErr bool
+	}{
+		{
+			name:  "defaults",
+			query: "protocol=icmp&target=10.233.64.12",
+			want:  &Request{Protocol: ICMP, TargetIP: "10.233.64.12", Timeout: 3, Count: 1, Interval: 1},
+		},
+		{
+			name:  "ping options",
+			query: "protocol=icmp&target=fd11:1111:1111:15::2a&timeout=5&count=10&interval=0.2&packetSize=1400",
+			want:  &Request{Protocol: ICMP, TargetIP: "fd11:1111:1111:15::2a", Timeout: 5, Count: 10, Interval: 0.2, PacketSize: 1400},
+		},
+		{
+			name:  "http service name",
+			query: "protocol=http&target=clusterlink-floater.kosmos-system.svc.cluster.local&port=8889",
+			want:  &Request{Protocol: HTTP, TargetIP: "clusterlink-floater.kosmos-system.svc.cluster.local", Port: "8889", Timeout: 3, Count: 1, Interval: 1},
+		},
+		{
+			name:  "dns",
+			query: "protocol=dns&target=10.96.0.10&name=kubernetes.default&expect=10.96.0.1",
+			want:  &Request{Protocol: DNS, TargetIP: "10.96.0.10", Name: "kubernetes.default", Expect: "10.96.0.1", Timeout: 3, Count: 1, Interval: 1},
+		},
+		{
+			name:    "tcp needs an ip",
+			query:   "protocol=tcp&target=clusterlink-floater&port=8889",
+			wantErr: true,
+		},
+		{
+			name:    "http external name",
+			query:   "protocol=http&target=example.com&port=8889",
+			wantErr: true,
+		},
+		{
+			name:    "http name with a port",
+			query:   "protocol=http&target=floater.kosmos-system.svc.cluster.local:22&port=8889",
+			wantErr: true,
+		},
+		{
+			name:    "loopback",
+			query:   "protocol=tcp&target=127.0.0.1&port=8889",
+			wantErr: true,
+		},
+		{
+			name:    "metadata service",
+			query:   "protocol=http&target=169.254.169.254&port=8889",
+			wantErr: true,
+		},
+		{
+			name:    "missing target",
+			query:   "protocol=http",
+			wantErr: true,
+		},
+		{
+			name:    "dns without name",
+			query:   "protocol=dns&target=10.96.0.10",
+			wantErr: true,
+		},
+		{
+			name:    "invalid count",
+			query:   "protocol=icmp&target=10.233.64.12&count=three",
+			wantErr: true,
+		},
+		{
+			name:    "zero timeout",
+			query:   "protocol=icmp&target=10.233.64.12&timeout=0",
+			wantErr: true,
+		},
+		{
+			name:    "negative packet size",
+			query:   "protocol=mtu&target=10.233.64.12&packetSize=-1",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ParseRequest(query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseRequest() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRequestParams checks that a request survives the query of the probe API.
+func TestRequestParams(t *testing.T) {
+	want := &Request{Protocol: UDP, TargetIP: "10.233.64.12", Port: "8889", Timeout: 2, Count: 4, Interval: 0.5, PacketSize: 64}
+
+	query := url.Values{}
+	for k, v := range want.Params() {
+		query.Set(k, v)
+	}
+	got, err := ParseRequest(query)
+	if err != nil {
+		t.Fatalf("ParseRequest() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseRequest(Params()) = %+v, want %+v", got, want)
+	}
+}
+
+func TestRequestAuthorize(t *testing.T) {
+	allowed := []*Request{
+		{Protocol: TCP, TargetIP: "10.233.64.12", Port: "8889"},
+		{Protocol: HTTP, TargetIP: "clusterlink-floater.kosmos-system.svc.cluster.local", Port: "8889"},
+		{Protocol: ICMP, TargetIP: "10.233.64.12"},
+		{Protocol: DNS, TargetIP: "10.96.0.10", Name: "kubernetes.default"},
+	}
+	for _, r := range allowed {
+		if err := r.Authorize("8889"); err != nil {
+			t.Errorf("Authorize(%+v) error = %v", r, err)
+		}
+	}
+
+	denied := []*Request{
+		{Protocol: TCP, TargetIP: "10.233.64.12", Port: "22"},
+		{Protocol: UDP, TargetIP: "10.233.64.12"},
+		{Protocol: HTTP, TargetIP: "10.96.0.1", Port: "443"},
+		{Protocol: DNS, TargetIP: "10.96.0.10", Port: "8889", Name: "kubernetes.default"},
+	}
+	for _, r := range denied {
+		if err := r.Authorize("8889"); err == nil {
+			t.Errorf("Authorize(%+v) allowed a port other than the floater port", r)
+		}
+	}
+}
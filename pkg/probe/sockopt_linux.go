@@ -0,0 +1,34 @@
+//go:build linux
+
+package probe
+
+import (
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// setDontFragment makes the kernel set the DF bit and refuse to fragment the echo requests.
+func setDontFragment(conn net.PacketConn, ipv6 bool) error {
+	sc, ok := conn.(syscall.Conn)
+	if !ok {
+		return fmt.Errorf("%T does not expose its socket", conn)
+	}
+	raw, err := sc.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var sockErr error
+	err = raw.Control(func(fd uintptr) {
+		if ipv6 {
+			sockErr = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IPV6, syscall.IPV6_MTU_DISCOVER, syscall.IPV6_PMTUDISC_DO)
+		} else {
+			sockErr = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IP, syscall.IP_MTU_DISCOVER, syscall.IP_PMTUDISC_DO)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return sockErr
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package probe
+
+import (
+	"fmt"
+	"net"
+	"runtime"
+)
+
+func setDontFragment(_ net.PacketConn, _ bool) error {
+	return fmt.Errorf("path MTU discovery is not supported on %s", runtime.GOOS)
+}
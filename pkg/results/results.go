@@ -0,0 +1,87 @@
+// Package results holds the outcome of a probe, it is shared by linkctl, which parses it from the output of
+// the floater tools, and by the floater, which runs the probes natively.
+package results
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	ExecError = iota
+	CommandSuccessed
+	CommandFailed
+)
+
+// UDPEchoPayload is the datagram the floaters echo back.
+const UDPEchoPayload = "kosmos-floater-udp-echo"
+
+type Result struct {
+	Status    int
+	ResultStr string
+	// Elapsed is the duration of the probe measured in the floater when it can, like by the probe API or the dns
+	// command, otherwise the round trip seen by linkctl
+	Elapsed time.Duration
+	// Statistics is only set by probes that measure latency and loss
+	Statistics *PingStatistics `json:",omitempty"`
+	// MTU is only set by the path MTU probe
+	MTU *MTUStatistics `json:",omitempty"`
+}
+
+// PingStatistics latencies are in milliseconds, Loss is a percentage.
+type PingStatistics struct {
+	Transmitted int     `json:"transmitted"`
+	Received    int     `json:"received"`
+	Loss        float64 `json:"loss"`
+	Min         float64 `json:"min"`
+	Avg         float64 `json:"avg"`
+	Max         float64 `json:"max"`
+	Mdev        float64 `json:"mdev"`
+}
+
+type MTUStatistics struct {
+	// PathMTU is the largest IP packet that got through
+	PathMTU int `json:"pathMTU"`
+	// InterfaceMTU is the MTU of the interface routing to the target
+	InterfaceMTU int `json:"interfaceMTU"`
+}
+
+func ParseError(err error) *Result {
+	return &Result{
+		Status:    ExecError,
+		ResultStr: fmt.Sprintf("exec error: %s", err),
+	}
+}
+
+func PrintStatus(status int) string {
+	if status == ExecError {
+		return "EXCEPTION"
+	}
+	if status == CommandSuccessed {
+		return "SUCCESSED"
+	}
+	if status == CommandFailed {
+		return "FAILED"
+	}
+	return "UNEXCEPTIONED"
+}
+
+// NewDNSResult checks the addresses name resolved to, they must contain expect when it is set.
+func NewDNSResult(name, server, expect string, addresses []string) *Result {
+	r := &Result{
+		Status:    CommandSuccessed,
+		ResultStr: fmt.Sprintf("%s resolved to %s by %s", name, strings.Join(addresses, ", "), server),
+	}
+	if len(expect) == 0 {
+		return r
+	}
+	for _, address := range addresses {
+		if address == expect {
+			return r
+		}
+	}
+	r.Status = CommandFailed
+	r.ResultStr = fmt.Sprintf("%s, expected %s", r.ResultStr, expect)
+	return r
+}
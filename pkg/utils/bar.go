@@ -1,14 +1,25 @@
 package utils
 
 import (
+	"io"
+
 	"github.com/k0kubun/go-ansi"
 	progressbar "github.com/schollz/progressbar/v3"
 )
 
 func NewBar(max int) *progressbar.ProgressBar {
+	return NewBarWithWriter(max, ansi.NewAnsiStdout())
+}
+
+// NewStderrBar keeps stdout clean for machine readable output.
+func NewStderrBar(max int) *progressbar.ProgressBar {
+	return NewBarWithWriter(max, ansi.NewAnsiStderr())
+}
+
+func NewBarWithWriter(max int, writer io.Writer) *progressbar.ProgressBar {
 	bar := progressbar.NewOptions(max,
 		progressbar.OptionShowElapsedTimeOnFinish(),
-		progressbar.OptionSetWriter(ansi.NewAnsiStdout()),
+		progressbar.OptionSetWriter(writer),
 		progressbar.OptionEnableColorCodes(true),
 		// progressbar.OptionShowBytes(true),
 		progressbar.OptionSetWidth(80),